@@ -0,0 +1,359 @@
+package jlib
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// archiveExtensions lists the archive types Install can extract, matching the Disco archive_type values
+var archiveExtensions = []string{"tar.gz", "tgz", "zip"}
+
+// preferredArchiveTypes returns the supported archive types ordered by preference for the current platform
+func preferredArchiveTypes() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"zip", "tar.gz", "tgz"}
+	}
+	return []string{"tar.gz", "tgz", "zip"}
+}
+
+// archiveType returns the archive type of a filename or an empty string if it is not supported
+func archiveType(filename string) string {
+	lower := strings.ToLower(filename)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, "."+ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// archiveBaseName returns the filename without its archive extension
+func archiveBaseName(filename string) string {
+	if ext := archiveType(filename); ext != "" {
+		return filename[:len(filename)-len(ext)-1]
+	}
+	return filename
+}
+
+// extractArchive extracts a zip or tar.gz archive into dest depending on its extension
+func extractArchive(ctx context.Context, src, dest string) error {
+	switch archiveType(src) {
+	case "zip":
+		return unzip(ctx, src, dest)
+	case "tar.gz", "tgz":
+		return untar(ctx, src, dest)
+	}
+	return fmt.Errorf("unsupported archive type: %s", filepath.Base(src))
+}
+
+// safeJoin joins name to dest and fails if the result escapes dest
+func safeJoin(dest, name string) (string, error) {
+	path := filepath.Join(dest, name)
+	if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("illegal file path: %s", path)
+	}
+	return path, nil
+}
+
+// checkLinkTarget fails if a symlink at path pointing to target would resolve outside of dest
+func checkLinkTarget(dest, path, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("illegal link target: %s -> %s", path, target)
+	}
+	resolved := filepath.Join(filepath.Dir(path), target)
+	if !strings.HasPrefix(resolved, filepath.Clean(dest)+string(os.PathSeparator)) {
+		return fmt.Errorf("illegal link target: %s -> %s", path, target)
+	}
+	return nil
+}
+
+// isWithin reports whether the clean path is root or inside it
+func isWithin(root, path string) bool {
+	return path == root || strings.HasPrefix(path, root+string(os.PathSeparator))
+}
+
+// realPath resolves the symlinks of the deepest existing ancestor of path, the missing directories
+// below it are created by the extraction and cannot be links yet. It fails if the result is outside of root,
+// the real path of the extraction directory: lexical checks alone miss chains of links created by earlier
+// entries, e.g. a/s -> ../q followed by a/s/l -> ../../outside.
+func realPath(root, path string) (string, error) {
+	dir, missing := path, ""
+	for {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		missing = filepath.Join(filepath.Base(dir), missing)
+		dir = filepath.Dir(dir)
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("illegal file path: %s: %w", path, err)
+	}
+	real = filepath.Join(real, missing)
+	if !isWithin(root, real) {
+		return "", fmt.Errorf("illegal file path: %s", path)
+	}
+	return real, nil
+}
+
+// prepareEntry checks that the real parent directory of path is inside root and removes the file or link
+// an earlier entry left at path, so that the entry replaces it instead of being written through it.
+// It returns the real path of the entry.
+func prepareEntry(root, path string) (string, error) {
+	parent, err := realPath(root, filepath.Dir(path))
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Lstat(path); err == nil && !info.IsDir() {
+		if err := os.Remove(path); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(parent, filepath.Base(path)), nil
+}
+
+// dirTime records the modification time of a directory, applied once its content is extracted
+type dirTime struct {
+	path    string
+	modTime time.Time
+}
+
+// setDirTimes applies directory modification times deepest first, writing into a directory changes its time
+func setDirTimes(dirs []dirTime) error {
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if dirs[i].modTime.IsZero() {
+			continue
+		}
+		if err := os.Chtimes(dirs[i].path, dirs[i].modTime, dirs[i].modTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unzip extracts a zip archive into dest, reproducing directories, symlinks,
+// Unix permission bits stored in the external attributes and modification times
+func unzip(ctx context.Context, src, dest string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return err
+	}
+
+	// Closure to address file descriptors issue with all the deferred .Close() methods
+	extractFile := func(f *zip.File, path string, real string) error {
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		mode := f.Mode()
+		if mode&os.ModeSymlink != 0 {
+			target, err := io.ReadAll(io.LimitReader(rc, 4096))
+			if err != nil {
+				return err
+			}
+			if err := checkLinkTarget(root, real, string(target)); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			return os.Symlink(string(target), path)
+		}
+
+		perm := mode.Perm()
+		if perm == 0 {
+			perm = 0644
+		}
+		if err := writeFile(path, &contextReader{ctx: ctx, r: rc}, perm); err != nil {
+			return err
+		}
+		return os.Chtimes(path, f.Modified, f.Modified)
+	}
+
+	var dirs []dirTime
+	for _, f := range r.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Check for ZipSlip (Directory traversal)
+		path, err := safeJoin(dest, f.Name)
+		if err != nil {
+			return err
+		}
+		real, err := prepareEntry(root, path)
+		if err != nil {
+			return err
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirTime{path: path, modTime: f.Modified})
+			continue
+		}
+		if err := extractFile(f, path, real); err != nil {
+			return err
+		}
+	}
+
+	return setDirTimes(dirs)
+}
+
+func untar(ctx context.Context, src, dest string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return err
+	}
+
+	var dirs []dirTime
+	tr := tar.NewReader(&contextReader{ctx: ctx, r: gz})
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return setDirTimes(dirs)
+		}
+		if err != nil {
+			return err
+		}
+
+		path, err := safeJoin(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		real, err := prepareEntry(root, path)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+			dirs = append(dirs, dirTime{path: path, modTime: header.ModTime})
+		case tar.TypeReg:
+			if err := writeFile(path, tr, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+			if err := os.Chtimes(path, header.ModTime, header.ModTime); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := checkLinkTarget(root, real, header.Linkname); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			target, err := safeJoin(dest, header.Linkname)
+			if err != nil {
+				return err
+			}
+			// The target itself may be a link, it must resolve inside dest as well
+			if _, err := realPath(root, target); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := os.Link(target, path); err != nil {
+				return err
+			}
+		default:
+			// Devices, fifos and the like have no place in a JDK
+		}
+	}
+}
+
+// writeFile creates the file at path, and its parent directories, with the content of r
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// flattenSingleDir moves the content of the only directory inside dir up into dir.
+// Archives usually wrap the JDK in a directory whose name differs from the archive name,
+// e.g. OpenJDK17U-jdk_x64_linux_hotspot_17.0.9_9.tar.gz contains jdk-17.0.9+9/.
+func flattenSingleDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return nil
+	}
+
+	// Rename first, the inner directory may contain an entry with its own name
+	inner, err := os.MkdirTemp(dir, ".flatten-")
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(inner); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(dir, entries[0].Name()), inner); err != nil {
+		return err
+	}
+
+	children, err := os.ReadDir(inner)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		if err := os.Rename(filepath.Join(inner, child.Name()), filepath.Join(dir, child.Name())); err != nil {
+			return err
+		}
+	}
+	return os.Remove(inner)
+}
@@ -0,0 +1,264 @@
+package jlib
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testArchiveEntry describes a file, directory or link of a synthetic archive
+type testArchiveEntry struct {
+	Name     string
+	Body     string
+	Mode     int64
+	Type     byte   // tar type flag, tar.TypeReg if zero
+	Linkname string // Target of symlinks and hard links
+}
+
+func makeTestTarGz(t *testing.T, entries ...testArchiveEntry) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.Name, Mode: e.Mode, Typeflag: e.Type, Linkname: e.Linkname}
+		if header.Typeflag == 0 {
+			header.Typeflag = tar.TypeReg
+		}
+		if header.Mode == 0 {
+			header.Mode = 0644
+		}
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(e.Body))
+		}
+		assert.NoError(t, tw.WriteHeader(header))
+		if header.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(e.Body))
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// testZipEntry describes an entry of a synthetic zip archive, Body is the target of symlinks
+type testZipEntry struct {
+	Name     string
+	Body     string
+	Mode     os.FileMode
+	Modified time.Time
+}
+
+func makeTestZipEntries(t *testing.T, entries ...testZipEntry) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.Name, Method: zip.Deflate, Modified: e.Modified}
+		header.SetMode(e.Mode)
+		w, err := zw.CreateHeader(header)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(e.Body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func writeTestArchive(t *testing.T, name string, data []byte) string {
+	file := path.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(file, data, 0644))
+	return file
+}
+
+func TestUntar(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	ctx := context.Background()
+
+	src := writeTestArchive(t, "jdk.tar.gz", makeTestTarGz(t,
+		testArchiveEntry{Name: "jdk-17.0.9+9/", Type: tar.TypeDir, Mode: 0755},
+		testArchiveEntry{Name: "jdk-17.0.9+9/bin/java", Body: "java", Mode: 0755},
+		testArchiveEntry{Name: "jdk-17.0.9+9/lib/libjli.so", Body: "lib"},
+		testArchiveEntry{Name: "jdk-17.0.9+9/lib/jli", Type: tar.TypeSymlink, Linkname: "libjli.so"},
+		testArchiveEntry{Name: "jdk-17.0.9+9/bin/java2", Type: tar.TypeLink, Linkname: "jdk-17.0.9+9/bin/java"},
+	))
+	dest := t.TempDir()
+	assert.NoError(t, extractArchive(ctx, src, dest))
+
+	info, err := os.Stat(path.Join(dest, "jdk-17.0.9+9", "bin", "java"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	target, err := os.Readlink(path.Join(dest, "jdk-17.0.9+9", "lib", "jli"))
+	assert.NoError(t, err)
+	assert.Equal(t, "libjli.so", target)
+
+	data, err := os.ReadFile(path.Join(dest, "jdk-17.0.9+9", "bin", "java2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "java", string(data))
+
+	for name, entry := range map[string]testArchiveEntry{
+		"traversal":    {Name: "../evil", Body: "x"},
+		"absoluteLink": {Name: "jdk/link", Type: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		"escapingLink": {Name: "jdk/link", Type: tar.TypeSymlink, Linkname: "../../etc/passwd"},
+		"escapingHard": {Name: "jdk/link", Type: tar.TypeLink, Linkname: "../outside"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := writeTestArchive(t, "evil.tgz", makeTestTarGz(t, entry))
+			assert.Error(t, extractArchive(ctx, src, t.TempDir()))
+		})
+	}
+}
+
+// chainedLinkEntries write outside of the extraction directory through links that each look harmless on their own:
+// s points to dest/q, and l, relative to the real directory of s, points two levels above dest
+var chainedLinkEntries = []testArchiveEntry{
+	{Name: "q/", Type: tar.TypeDir, Mode: 0755},
+	{Name: "x/y/z/s", Type: tar.TypeSymlink, Linkname: "../../../q"},
+	{Name: "x/y/z/s/l", Type: tar.TypeSymlink, Linkname: "../../../pwn"},
+	{Name: "x/y/z/s/l/owned.txt", Body: "owned"},
+}
+
+// hardLinkEntries overwrite a file outside of the extraction directory through a hard link made via chained links
+var hardLinkEntries = []testArchiveEntry{
+	{Name: "q/", Type: tar.TypeDir, Mode: 0755},
+	{Name: "x/y/z/s", Type: tar.TypeSymlink, Linkname: "../../../q"},
+	{Name: "x/y/z/s/l", Type: tar.TypeSymlink, Linkname: "../../.."},
+	{Name: "h", Type: tar.TypeLink, Linkname: "x/y/z/s/l/secret"},
+	{Name: "h", Body: "owned"},
+}
+
+// assertNotEscaped extracts into dest two levels below a temporary root holding pwn/ and secret,
+// and checks that the extraction fails without writing outside of dest
+func assertNotEscaped(t *testing.T, extract func(dest string) error) {
+	root := t.TempDir()
+	dest := path.Join(root, "a", "b", "dest")
+	assert.NoError(t, os.MkdirAll(dest, 0755))
+	assert.NoError(t, os.MkdirAll(path.Join(root, "a", "pwn"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(root, "a", "secret"), []byte("secret"), 0644))
+
+	assert.Error(t, extract(dest))
+	assert.NoFileExists(t, path.Join(root, "a", "pwn", "owned.txt"))
+	data, err := os.ReadFile(path.Join(root, "a", "secret"))
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(data))
+}
+
+func TestUntarChainedLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	ctx := context.Background()
+
+	for name, entries := range map[string][]testArchiveEntry{
+		"symlink":  chainedLinkEntries,
+		"hardLink": hardLinkEntries,
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := writeTestArchive(t, "evil.tgz", makeTestTarGz(t, entries...))
+			assertNotEscaped(t, func(dest string) error { return extractArchive(ctx, src, dest) })
+		})
+	}
+}
+
+func TestUnzip(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks and permission bits are not supported on windows")
+	}
+	ctx := context.Background()
+	modified := time.Date(2023, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	src := writeTestArchive(t, "jdk.zip", makeTestZipEntries(t,
+		testZipEntry{Name: "jdk/", Mode: os.ModeDir | 0700, Modified: modified},
+		testZipEntry{Name: "jdk/bin/java", Body: "java", Mode: 0755, Modified: modified},
+		testZipEntry{Name: "jdk/legal/java.base/LICENSE", Body: "license", Mode: 0444, Modified: modified},
+		testZipEntry{Name: "jdk/legal/java.sql/LICENSE", Body: "../java.base/LICENSE", Mode: os.ModeSymlink | 0777, Modified: modified},
+	))
+	dest := t.TempDir()
+	assert.NoError(t, extractArchive(ctx, src, dest))
+
+	info, err := os.Stat(path.Join(dest, "jdk"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	assert.True(t, info.ModTime().Equal(modified))
+
+	info, err = os.Stat(path.Join(dest, "jdk", "bin", "java"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	assert.True(t, info.ModTime().Equal(modified))
+
+	info, err = os.Stat(path.Join(dest, "jdk", "legal", "java.base", "LICENSE"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0444), info.Mode().Perm())
+
+	target, err := os.Readlink(path.Join(dest, "jdk", "legal", "java.sql", "LICENSE"))
+	assert.NoError(t, err)
+	assert.Equal(t, "../java.base/LICENSE", target)
+	data, err := os.ReadFile(path.Join(dest, "jdk", "legal", "java.sql", "LICENSE"))
+	assert.NoError(t, err)
+	assert.Equal(t, "license", string(data))
+
+	for name, entry := range map[string]testZipEntry{
+		"traversal":    {Name: "../evil", Body: "x", Mode: 0644},
+		"absoluteLink": {Name: "jdk/link", Body: "/etc/passwd", Mode: os.ModeSymlink | 0777},
+		"escapingLink": {Name: "jdk/link", Body: "../../etc/passwd", Mode: os.ModeSymlink | 0777},
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := writeTestArchive(t, "evil.zip", makeTestZipEntries(t, entry))
+			assert.Error(t, extractArchive(ctx, src, t.TempDir()))
+		})
+	}
+}
+
+func TestFlattenSingleDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(path.Join(dir, "jdk", "jdk"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(dir, "jdk", "release"), nil, 0644))
+
+	assert.NoError(t, flattenSingleDir(dir))
+	assert.FileExists(t, path.Join(dir, "release"))
+	assert.DirExists(t, path.Join(dir, "jdk"))
+
+	// More than one entry is left untouched
+	assert.NoError(t, flattenSingleDir(dir))
+	assert.FileExists(t, path.Join(dir, "release"))
+}
+
+func TestArchiveBaseName(t *testing.T) {
+	assert.Equal(t, "OpenJDK17U-jdk_x64_linux_hotspot_17.0.9_9", archiveBaseName("OpenJDK17U-jdk_x64_linux_hotspot_17.0.9_9.tar.gz"))
+	assert.Equal(t, "zulu8.74.0.17-ca-jdk8.0.392-linux_x64", archiveBaseName("zulu8.74.0.17-ca-jdk8.0.392-linux_x64.zip"))
+	assert.Equal(t, "jdk", archiveBaseName("jdk.TGZ"))
+	assert.Equal(t, "jdk.msi", archiveBaseName("jdk.msi"))
+}
+
+func TestUnzipChainedLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks are not supported on windows")
+	}
+	ctx := context.Background()
+
+	links := []testZipEntry{
+		{Name: "x/y/z/s", Body: "../../../q", Mode: os.ModeSymlink | 0777},
+		{Name: "x/y/z/s/l", Body: "../../../pwn", Mode: os.ModeSymlink | 0777},
+		{Name: "x/y/z/s/l/owned.txt", Body: "owned", Mode: 0644},
+	}
+	for name, entries := range map[string][]testZipEntry{
+		"danglingLink": links,
+		"existingDir":  append([]testZipEntry{{Name: "q/", Mode: os.ModeDir | 0755}}, links...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			src := writeTestArchive(t, "evil.zip", makeTestZipEntries(t, entries...))
+			assertNotEscaped(t, func(dest string) error { return extractArchive(ctx, src, dest) })
+		})
+	}
+}
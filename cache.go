@@ -0,0 +1,205 @@
+package jlib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DiscoCache is a persistent cache of Disco API responses keyed by the request URL.
+// Expired entries are revalidated with ETag/If-Modified-Since, and with StaleIfError
+// they are still served when the API cannot be reached or answers with a transient error.
+type DiscoCache struct {
+	Dir          string                   // Directory holding the cache entries
+	DefaultTTL   time.Duration            // Time an entry is served without revalidation, unless overridden in TTL
+	TTL          map[string]time.Duration // TTL per endpoint, keyed by the first path segment, e.g. "packages" or "ids"
+	StaleIfError bool                     // Serve expired entries when the request fails with a transient error
+}
+
+func NewDiscoCache(dir string) *DiscoCache {
+	return &DiscoCache{
+		Dir:        dir,
+		DefaultTTL: 24 * time.Hour,
+		TTL: map[string]time.Duration{
+			"packages":       time.Hour,
+			"major_versions": 6 * time.Hour,
+			"ids":            7 * 24 * time.Hour,
+			"parameters":     7 * 24 * time.Hour,
+		},
+		StaleIfError: true,
+	}
+}
+
+type cacheEntry struct {
+	Key          string    `json:"key"`
+	StoredAt     time.Time `json:"stored_at"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Body         []byte    `json:"body,omitempty"`
+	Location     string    `json:"location,omitempty"` // Final URL of a followed redirect
+}
+
+func (dc *DiscoCache) ttl(endpoint string) time.Duration {
+	if ttl, ok := dc.TTL[endpoint]; ok {
+		return ttl
+	}
+	return dc.DefaultTTL
+}
+
+func (dc *DiscoCache) fresh(entry *cacheEntry, endpoint string) bool {
+	return time.Since(entry.StoredAt) < dc.ttl(endpoint)
+}
+
+func (dc *DiscoCache) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the entry stored for key or nil if there is none or it is unreadable
+func (dc *DiscoCache) load(key string) *cacheEntry {
+	entry, err := readStructFromJSONFile[cacheEntry](dc.entryPath(key))
+	if err != nil || entry == nil || entry.Key != key {
+		return nil
+	}
+	return entry
+}
+
+// store writes the entry through a temporary file so concurrent readers never see partial entries
+func (dc *DiscoCache) store(entry *cacheEntry) error {
+	if err := os.MkdirAll(dc.Dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dc.Dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(entry); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dc.entryPath(entry.Key))
+}
+
+// Clear removes all cached responses
+func (dc *DiscoCache) Clear() error {
+	return os.RemoveAll(dc.Dir)
+}
+
+// isTransient reports whether a failed request may be answered from a stale cache entry
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *DiscoAPIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	return true
+}
+
+// fetch returns the body of a successful GET of u, going through the cache when the client has one
+func (c *DiscoClient) fetch(ctx context.Context, endpoint string, u string) ([]byte, error) {
+	if c.Cache == nil {
+		return c.fetchBody(ctx, u, nil)
+	}
+
+	entry := c.Cache.load(u)
+	if entry != nil && c.Cache.fresh(entry, endpoint) {
+		return entry.Body, nil
+	}
+
+	header := http.Header{}
+	if entry != nil {
+		if entry.ETag != "" {
+			header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := c.getWithHeader(ctx, u, header)
+	if err != nil {
+		if entry != nil && c.Cache.StaleIfError && isTransient(err) {
+			return entry.Body, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		entry.StoredAt = time.Now()
+		c.Cache.store(entry)
+		return entry.Body, nil
+	}
+
+	body, err := readResponse(resp)
+	if err != nil {
+		if entry != nil && c.Cache.StaleIfError && isTransient(err) {
+			return entry.Body, nil
+		}
+		return nil, err
+	}
+
+	// A cache that cannot be written must not fail the request
+	c.Cache.store(&cacheEntry{
+		Key:          u,
+		StoredAt:     time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         body,
+	})
+	return body, nil
+}
+
+// resolveRedirect returns the final URL of a GET of u without reading the body, using the cache when the client has one
+func (c *DiscoClient) resolveRedirect(ctx context.Context, endpoint string, u string) (string, error) {
+	var entry *cacheEntry
+	if c.Cache != nil {
+		entry = c.Cache.load(u)
+		if entry != nil && entry.Location != "" && c.Cache.fresh(entry, endpoint) {
+			return entry.Location, nil
+		}
+	}
+
+	location, err := c.followRedirect(ctx, u)
+	if err != nil {
+		if entry != nil && entry.Location != "" && c.Cache.StaleIfError && isTransient(err) {
+			return entry.Location, nil
+		}
+		return "", err
+	}
+
+	if c.Cache != nil {
+		c.Cache.store(&cacheEntry{Key: u, StoredAt: time.Now(), Location: location})
+	}
+	return location, nil
+}
+
+func (c *DiscoClient) fetchBody(ctx context.Context, u string, header http.Header) ([]byte, error) {
+	resp, err := c.getWithHeader(ctx, u, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return readResponse(resp)
+}
+
+func readResponse(resp *http.Response) ([]byte, error) {
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
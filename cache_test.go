@@ -0,0 +1,131 @@
+package jlib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCachedTestClient(t *testing.T, handler http.HandlerFunc) (*DiscoClient, *httptest.Server) {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c := NewDiscoClient(srv.URL)
+	c.Retry = nil
+	c.Cache = NewDiscoCache(t.TempDir())
+	return c, srv
+}
+
+func TestDiscoCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Fresh", func(t *testing.T) {
+		var calls atomic.Int32
+		c, _ := newCachedTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Write([]byte(`{"result":[{"name":"Zulu","api_parameter":"zulu"}]}`))
+		})
+
+		for i := 0; i < 3; i++ {
+			result, err := c.GetDistributions(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, "zulu", result[0].ApiParameter)
+		}
+		assert.Equal(t, int32(1), calls.Load())
+
+		// A different query is a different entry
+		_, err := c.GetDistributions(ctx, &DistributionsOptions{IncludeVersions: true})
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Revalidate", func(t *testing.T) {
+		var calls, notModified atomic.Int32
+		c, _ := newCachedTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"result":[{"id":"abc"}]}`))
+		})
+		c.Cache.TTL["packages"] = 0
+
+		for i := 0; i < 3; i++ {
+			result, err := c.GetPackages(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, "abc", result[0].ID)
+		}
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Equal(t, int32(2), notModified.Load())
+	})
+
+	t.Run("StaleIfError", func(t *testing.T) {
+		var status atomic.Int32
+		status.Store(http.StatusOK)
+		c, _ := newCachedTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(int(status.Load()))
+			w.Write([]byte(`{"result":[{"id":"abc"}]}`))
+		})
+		c.Cache.TTL["packages"] = 0
+
+		_, err := c.GetPackages(ctx)
+		assert.NoError(t, err)
+
+		status.Store(http.StatusServiceUnavailable)
+		result, err := c.GetPackages(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", result[0].ID)
+
+		c.Cache.StaleIfError = false
+		_, err = c.GetPackages(ctx)
+		assert.Error(t, err)
+
+		// A 404 is an answer, not an outage
+		c.Cache.StaleIfError = true
+		status.Store(http.StatusNotFound)
+		_, err = c.GetPackages(ctx)
+		assert.True(t, IsNotFound(err))
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		var down atomic.Bool
+		c, srv := newCachedTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if down.Load() {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			if r.URL.Path == "/ids/abc/redirect" {
+				http.Redirect(w, r, "/files/jdk.zip", http.StatusFound)
+				return
+			}
+			w.Write([]byte("archive"))
+		})
+		c.Cache.TTL["ids"] = 0
+
+		location, err := c.GetPackageRedirect(ctx, "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, srv.URL+"/files/jdk.zip", location)
+
+		down.Store(true)
+		location, err = c.GetPackageRedirect(ctx, "abc")
+		assert.NoError(t, err)
+		assert.Equal(t, srv.URL+"/files/jdk.zip", location)
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		dc := NewDiscoCache(path.Join(t.TempDir(), "cache"))
+		assert.NoError(t, dc.store(&cacheEntry{Key: "k", StoredAt: time.Now(), Body: []byte("{}")}))
+		assert.NotNil(t, dc.load("k"))
+		assert.Nil(t, dc.load("other"))
+		assert.NoError(t, dc.Clear())
+		assert.Nil(t, dc.load("k"))
+	})
+}
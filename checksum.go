@@ -0,0 +1,102 @@
+package jlib
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// Checksum is an expected digest of a downloaded file
+type Checksum struct {
+	Type  string // Hash algorithm as named by the Disco API, e.g. "sha256"
+	Value string // Hex encoded digest
+}
+
+// ChecksumMismatchError is returned when a downloaded file does not match its published checksum.
+// The file is removed before the error is returned.
+type ChecksumMismatchError struct {
+	File     string // Path the file was downloaded to
+	Type     string // Hash algorithm
+	Expected string // Published digest
+	Actual   string // Digest of the downloaded data
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch for %s: expected %s, got %s", e.Type, e.File, e.Expected, e.Actual)
+}
+
+// newHash returns the hash computing the digest, or nil if the algorithm is unknown
+// and the file cannot be verified
+func (cs *Checksum) newHash() hash.Hash {
+	switch strings.ToLower(strings.ReplaceAll(cs.Type, "-", "")) {
+	case "sha256":
+		return sha256.New()
+	case "sha512":
+		return sha512.New()
+	case "sha1":
+		return sha1.New()
+	case "md5":
+		return md5.New()
+	}
+	return nil
+}
+
+// verify compares the digest computed by h against the expected value
+func (cs *Checksum) verify(h hash.Hash, file string) error {
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, cs.Value) {
+		return &ChecksumMismatchError{File: file, Type: cs.Type, Expected: cs.Value, Actual: actual}
+	}
+	return nil
+}
+
+// parseChecksumFile extracts the digest from a checksum file in the "<digest>  <filename>" or plain "<digest>" format
+func parseChecksumFile(data []byte) (string, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file")
+	}
+	if _, err := hex.DecodeString(fields[0]); err != nil {
+		return "", fmt.Errorf("invalid checksum %q", fields[0])
+	}
+	return fields[0], nil
+}
+
+// GetPackageChecksum returns the published checksum of the package defined by the given id.
+// It returns nil if the distribution does not publish a checksum.
+func (c *DiscoClient) GetPackageChecksum(ctx context.Context, id string) (*Checksum, error) {
+	info, err := c.GetPackageInfo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return c.packageChecksum(ctx, info)
+}
+
+// packageChecksum returns the checksum of the package info, downloading it from
+// the checksum_uri if the API does not report it directly
+func (c *DiscoClient) packageChecksum(ctx context.Context, info *PackageInfo) (*Checksum, error) {
+	if info.ChecksumType == "" {
+		return nil, nil
+	}
+	value := info.Checksum
+	if value == "" && info.ChecksumURI != "" {
+		data, err := c.fetchBody(ctx, info.ChecksumURI, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download checksum: %w", err)
+		}
+		if value, err = parseChecksumFile(data); err != nil {
+			return nil, err
+		}
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	return &Checksum{Type: info.ChecksumType, Value: value}, nil
+}
@@ -0,0 +1,112 @@
+package jlib
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadJavaByIDChecksum(t *testing.T) {
+	ctx := context.Background()
+	pkg := testPackage{
+		GetPackagesResponse: GetPackagesResponse{ID: "good", Filename: "good.zip"},
+		Archive:             []byte("archive data"),
+	}
+	bad := testPackage{
+		GetPackagesResponse: GetPackagesResponse{ID: "bad", Filename: "bad.zip"},
+		Archive:             []byte("tampered data"),
+		Checksum:            "0000000000000000000000000000000000000000000000000000000000000000",
+	}
+	c := newTestDiscoClient(newTestDiscoServer(t, pkg, bad))
+
+	dst := t.TempDir()
+	file, err := c.DownloadJavaByID(ctx, "good", dst)
+	assert.NoError(t, err)
+	assert.FileExists(t, file.Name())
+
+	_, err = c.DownloadJavaByID(ctx, "bad", dst)
+	var mismatch *ChecksumMismatchError
+	assert.True(t, errors.As(err, &mismatch))
+	assert.Equal(t, bad.Checksum, mismatch.Expected)
+	assert.NoFileExists(t, path.Join(dst, "bad.zip"))
+}
+
+func TestDownloadJavaByIDChecksumTypes(t *testing.T) {
+	ctx := context.Background()
+	archive := []byte("archive data")
+	sha512Sum := sha512.Sum512(archive)
+	md5Sum := md5.Sum(archive)
+	c := newTestDiscoClient(newTestDiscoServer(t,
+		testPackage{
+			GetPackagesResponse: GetPackagesResponse{ID: "sha512", Filename: "sha512.zip"},
+			Archive:             archive,
+			Checksum:            hex.EncodeToString(sha512Sum[:]),
+			ChecksumType:        "sha512",
+		},
+		testPackage{
+			GetPackagesResponse: GetPackagesResponse{ID: "md5", Filename: "md5.zip"},
+			Archive:             []byte("tampered data"),
+			Checksum:            hex.EncodeToString(md5Sum[:]),
+			ChecksumType:        "md5",
+		},
+		testPackage{
+			GetPackagesResponse: GetPackagesResponse{ID: "unknown", Filename: "unknown.zip"},
+			Archive:             archive,
+			Checksum:            "0000",
+			ChecksumType:        "crc32c",
+		},
+	))
+
+	dst := t.TempDir()
+	file, err := c.DownloadJavaByID(ctx, "sha512", dst)
+	assert.NoError(t, err)
+	assert.FileExists(t, file.Name())
+
+	_, err = c.DownloadJavaByID(ctx, "md5", dst)
+	var mismatch *ChecksumMismatchError
+	assert.True(t, errors.As(err, &mismatch))
+
+	// An algorithm we do not know is not a reason to refuse the download
+	file, err = c.DownloadJavaByID(ctx, "unknown", dst)
+	assert.NoError(t, err)
+	assert.FileExists(t, file.Name())
+}
+
+func TestGetPackageChecksumURI(t *testing.T) {
+	sum := sha1.Sum([]byte("archive data"))
+	digest := hex.EncodeToString(sum[:])
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/ids/abc", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[{"checksum":"","checksum_type":"sha1","checksum_uri":"` + srv.URL + `/jdk.zip.sha1.txt"}]}`))
+	})
+	mux.HandleFunc("/jdk.zip.sha1.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(digest + "  jdk.zip\n"))
+	})
+
+	checksum, err := newTestDiscoClient(srv).GetPackageChecksum(context.Background(), "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, &Checksum{Type: "sha1", Value: digest}, checksum)
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	v, err := parseChecksumFile([]byte("ABCDEF01  OpenJDK17U-jdk_x64_linux_hotspot_17.0.9_9.tar.gz\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCDEF01", v)
+
+	_, err = parseChecksumFile([]byte("<html>not found</html>"))
+	assert.Error(t, err)
+	_, err = parseChecksumFile(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,587 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kunjude/jlib"
+)
+
+// packageJSON is the machine-readable form of an installed package printed with -json
+type packageJSON struct {
+	ID           string `json:"id"`
+	Distribution string `json:"distribution"`
+	JavaVersion  string `json:"java_version"`
+	PackageType  string `json:"package_type,omitempty"`
+	JavaFX       bool   `json:"javafx_bundled"`
+	Path         string `json:"path"`
+	JavaHome     string `json:"java_home"`
+	External     bool   `json:"external"`
+	Linked       bool   `json:"linked"`
+	Current      bool   `json:"current"`
+}
+
+func newPackageJSON(java *jlib.JavaPackage, current bool) packageJSON {
+	return packageJSON{
+		ID:           java.ID,
+		Distribution: java.Distribution,
+		JavaVersion:  java.JavaVersion,
+		PackageType:  java.PackageType,
+		JavaFX:       java.JavaFXBundled,
+		Path:         filepath.FromSlash(java.JavaDir),
+		JavaHome:     filepath.FromSlash(java.JavaHome),
+		External:     java.External,
+		Linked:       java.Linked,
+		Current:      current,
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// isCurrent reports whether java is the Java selected for the working directory
+func (a *app) isCurrent(java *jlib.JavaPackage) bool {
+	wd, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	selection, err := a.vm.Current(wd)
+	return err == nil && selection.Java.JavaDir == java.JavaDir
+}
+
+func runInstall(a *app, args []string) error {
+	flags := a.newFlagSet("install")
+	archive := flags.String("archive", "", "install a local zip or tar.gz JDK archive instead of downloading")
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	var java *jlib.JavaPackage
+	var err error
+	if *archive != "" {
+		if flags.NArg() > 0 {
+			return errors.New("a spec cannot be given with -archive")
+		}
+		java, err = a.vm.InstallFromArchiveContext(a.ctx, *archive, nil)
+	} else {
+		var spec *jlib.JavaSpec
+		spec, err = a.requestedSpec(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "Installing %s...\n", spec)
+		java, err = a.vm.InstallSpecContext(a.ctx, spec)
+	}
+	if jlib.IsInstalled(err) {
+		fmt.Fprintf(a.stdout, "%s is already installed in %s\n", java.ID, filepath.FromSlash(java.JavaDir))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Installed %s in %s\n", java.ID, filepath.FromSlash(java.JavaDir))
+	return nil
+}
+
+// requestedSpec parses arg, or returns the spec selected for the working directory when arg is empty
+func (a *app) requestedSpec(arg string) (*jlib.JavaSpec, error) {
+	if arg != "" {
+		return jlib.ParseJavaSpec(arg)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	selection, err := a.vm.Current(wd)
+	if err != nil && !errors.Is(err, jlib.ErrJavaNotFound) {
+		return nil, err
+	}
+	if selection.Spec == nil {
+		return nil, errors.New("no java requested, give a spec or add a .java-version file")
+	}
+	return selection.Spec, nil
+}
+
+func runUninstall(a *app, args []string) error {
+	flags := a.newFlagSet("uninstall")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	java, err := a.resolvePackage(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := a.vm.Remove(java); err != nil {
+		if errors.Is(err, jlib.ErrExternalPackage) {
+			return fmt.Errorf("%s was not installed by jlib: %w", filepath.FromSlash(java.JavaDir), err)
+		}
+		return err
+	}
+	if java.Linked {
+		fmt.Fprintf(a.stdout, "Unlinked %s\n", java.ID)
+	} else {
+		fmt.Fprintf(a.stdout, "Removed %s\n", java.ID)
+	}
+	return nil
+}
+
+func runLs(a *app, args []string) error {
+	flags := a.newFlagSet("ls")
+	jsonOutput := flags.Bool("json", false, "print JSON")
+	all := flags.Bool("all", false, "include the JDKs installed outside of jlib")
+	if err := parseFlags(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	result, err := a.vm.Scan()
+	if err != nil {
+		return err
+	}
+	for _, problem := range result.Problems {
+		fmt.Fprintf(a.stderr, "warning: %v\n", problem)
+	}
+	javas := result.Packages
+	if *all {
+		a.vm.IncludeExternal = true
+		if javas, err = a.vm.List(); err != nil {
+			return err
+		}
+	}
+
+	var current string
+	if wd, err := os.Getwd(); err == nil {
+		if selection, err := a.vm.Current(wd); err == nil {
+			current = selection.Java.JavaDir
+		}
+	}
+
+	if *jsonOutput {
+		list := []packageJSON{}
+		for _, java := range javas {
+			list = append(list, newPackageJSON(java, java.JavaDir == current))
+		}
+		return writeJSON(a.stdout, list)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\tID\tDISTRIBUTION\tVERSION\tTYPE\tPATH")
+	for _, java := range javas {
+		marker := ""
+		if java.JavaDir == current {
+			marker = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", marker, java.ID, java.Distribution, java.JavaVersion, java.PackageType, filepath.FromSlash(java.JavaDir))
+	}
+	return w.Flush()
+}
+
+func runLsRemote(a *app, args []string) error {
+	flags := a.newFlagSet("ls-remote")
+	jsonOutput := flags.Bool("json", false, "print JSON")
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+	value := flags.Arg(0)
+	if value == "" {
+		value = "latest"
+	}
+	spec, err := jlib.ParseJavaSpec(value)
+	if err != nil {
+		return err
+	}
+
+	packages, err := a.vm.Client.GetPackages(a.ctx, spec.InstallOptions())
+	if err != nil {
+		return err
+	}
+	matching := []jlib.GetPackagesResponse{}
+	for i := range packages {
+		if spec.Matches(&packages[i]) {
+			matching = append(matching, packages[i])
+		}
+	}
+
+	if *jsonOutput {
+		return writeJSON(a.stdout, matching)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDISTRIBUTION\tVERSION\tSTATUS\tTYPE\tFILENAME")
+	for _, pkg := range matching {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", pkg.ID, pkg.Distribution, pkg.JavaVersion, pkg.ReleaseStatus, pkg.PackageType, pkg.Filename)
+	}
+	return w.Flush()
+}
+
+// selectionFlags are the flags shared by use and default
+type selectionFlags struct {
+	install *bool
+	unset   *bool
+}
+
+func (a *app) newSelectionFlagSet(name string) (*flag.FlagSet, selectionFlags) {
+	flags := a.newFlagSet(name)
+	return flags, selectionFlags{
+		install: flags.Bool("install", false, "install the spec first when no installed Java matches"),
+		unset:   flags.Bool("unset", false, "remove the selection"),
+	}
+}
+
+// setSelection parses the spec argument of use and default, installs it when install is set and records it with set
+func (a *app) setSelection(flags selectionFlags, arg string, set func(spec *jlib.JavaSpec) error) (*jlib.JavaPackage, error) {
+	if *flags.unset {
+		return nil, set(nil)
+	}
+	spec, err := jlib.ParseJavaSpec(arg)
+	if err != nil {
+		return nil, err
+	}
+	if *flags.install {
+		if _, err := a.vm.UseOrInstallSpecContext(a.ctx, spec); err != nil && !jlib.IsInstalled(err) {
+			return nil, err
+		}
+	}
+	if err := set(spec); err != nil {
+		if errors.Is(err, jlib.ErrJavaNotFound) {
+			return nil, fmt.Errorf("%s is not installed, run \"jlib install %s\" or add -install", spec, spec)
+		}
+		return nil, err
+	}
+	return a.vm.UseSpec(spec)
+}
+
+func runUse(a *app, args []string) error {
+	flags, selection := a.newSelectionFlagSet("use")
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+	if !*selection.unset && flags.NArg() == 0 {
+		flags.Usage()
+		return &exitCodeError{code: 2}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	java, err := a.setSelection(selection, flags.Arg(0), func(spec *jlib.JavaSpec) error {
+		return a.vm.SetDirectory(wd, spec)
+	})
+	if err != nil {
+		return err
+	}
+	if java == nil {
+		fmt.Fprintf(a.stdout, "Removed the Java of %s\n", wd)
+		return nil
+	}
+	fmt.Fprintf(a.stdout, "Using %s in %s\n", java.ID, wd)
+	if project, err := jlib.FindProjectFile(wd); err == nil {
+		fmt.Fprintf(a.stderr, "warning: %s takes precedence over the directory selection\n", project.Path)
+	}
+	return nil
+}
+
+func runDefault(a *app, args []string) error {
+	flags, selection := a.newSelectionFlagSet("default")
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+	if !*selection.unset && flags.NArg() == 0 {
+		state, err := a.vm.LoadState()
+		if err != nil {
+			return err
+		}
+		if state.Default == "" {
+			return errors.New("no default set")
+		}
+		fmt.Fprintln(a.stdout, state.Default)
+		return nil
+	}
+	java, err := a.setSelection(selection, flags.Arg(0), a.vm.SetDefault)
+	if err != nil {
+		return err
+	}
+	if java == nil {
+		fmt.Fprintln(a.stdout, "Removed the default Java")
+		return nil
+	}
+	fmt.Fprintf(a.stdout, "Default set to %s\n", java.ID)
+	return nil
+}
+
+// currentJSON is the machine-readable form of a Selection
+type currentJSON struct {
+	Spec        string       `json:"spec,omitempty"`
+	Source      string       `json:"source"`
+	Origin      string       `json:"origin,omitempty"`
+	Explanation string       `json:"explanation"`
+	Installed   bool         `json:"installed"`
+	Java        *packageJSON `json:"java"`
+}
+
+func runCurrent(a *app, args []string) error {
+	flags := a.newFlagSet("current")
+	jsonOutput := flags.Bool("json", false, "print JSON")
+	if err := parseFlags(flags, args, 0, 0); err != nil {
+		return err
+	}
+	selection, err := a.current()
+	if !*jsonOutput || selection == nil {
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "%s (%s)\n", selection.Java.ID, selection.Explain())
+		return nil
+	}
+
+	out := currentJSON{Source: selection.Source, Origin: selection.Origin, Installed: selection.Java != nil}
+	if selection.Spec != nil {
+		out.Spec = selection.Spec.String()
+	}
+	if selection.Java != nil {
+		java := newPackageJSON(selection.Java, true)
+		out.Java = &java
+	}
+	out.Explanation = selection.Explain()
+	if err := writeJSON(a.stdout, out); err != nil {
+		return err
+	}
+	if !out.Installed {
+		return &exitCodeError{code: 1}
+	}
+	return nil
+}
+
+func runWhich(a *app, args []string) error {
+	flags := a.newFlagSet("which")
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+	name := flags.Arg(0)
+	if name == "" {
+		name = "java"
+	}
+	selection, err := a.current()
+	if err != nil {
+		return err
+	}
+	bin, err := exec.LookPath(filepath.Join(filepath.FromSlash(selection.Java.JavaHome), "bin", name))
+	if err != nil {
+		return fmt.Errorf("%s is not provided by %s", name, selection.Java.ID)
+	}
+	fmt.Fprintln(a.stdout, bin)
+	return nil
+}
+
+// defaultShell guesses the shell to render for from $SHELL, PowerShell on windows
+func defaultShell() string {
+	if runtime.GOOS == "windows" {
+		return jlib.ShellPowerShell
+	}
+	switch shell := filepath.Base(os.Getenv("SHELL")); shell {
+	case jlib.ShellZsh, jlib.ShellFish, "pwsh":
+		return shell
+	default:
+		return jlib.ShellBash
+	}
+}
+
+func runEnv(a *app, args []string) error {
+	flags := a.newFlagSet("env")
+	shell := flags.String("shell", defaultShell(), "shell to render for: bash, zsh, fish or powershell")
+	unset := flags.Bool("unset", false, "deactivate the Java activated by jlib")
+	if err := parseFlags(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	var java *jlib.JavaPackage
+	switch {
+	case *unset:
+	case flags.NArg() > 0:
+		var err error
+		if java, err = a.resolvePackage(flags.Arg(0)); err != nil {
+			return err
+		}
+	default:
+		selection, err := a.current()
+		if err != nil {
+			return err
+		}
+		java = selection.Java
+	}
+
+	script, err := jlib.RenderEnv(strings.ToLower(*shell), jlib.Env(java))
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(a.stdout, script)
+	return err
+}
+
+func runExec(a *app, args []string) error {
+	flags := a.newFlagSet("exec")
+	install := flags.Bool("install", false, "install the spec first when no installed Java matches")
+	var javaOptions stringsFlag
+	flags.Var(&javaOptions, "J", "JVM option passed in JAVA_TOOL_OPTIONS, may be repeated")
+	if err := parseFlags(flags, args, 2, -1); err != nil {
+		return err
+	}
+	spec, err := jlib.ParseJavaSpec(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	err = a.vm.Exec(spec, flags.Arg(1), flags.Args()[2:], &jlib.CommandOptions{Install: *install, JavaToolOptions: javaOptions})
+	if code, ok := exitCode(err); ok {
+		return &exitCodeError{code: code}
+	}
+	if errors.Is(err, jlib.ErrJavaNotFound) {
+		return fmt.Errorf("%s is not installed, run \"jlib install %s\" or add -install", spec, spec)
+	}
+	return err
+}
+
+// infoJSON is the machine-readable form of an installed package with its release file
+type infoJSON struct {
+	packageJSON
+	Release   map[string]string           `json:"release,omitempty"`
+	Signature *jlib.SignatureVerification `json:"signature,omitempty"`
+}
+
+// remoteInfoJSON is the machine-readable form of a Disco package with its download and verification links
+type remoteInfoJSON struct {
+	Package jlib.GetPackagesResponse `json:"package"`
+	Info    *jlib.PackageInfo        `json:"info"`
+}
+
+func runInfo(a *app, args []string) error {
+	flags := a.newFlagSet("info")
+	jsonOutput := flags.Bool("json", false, "print JSON")
+	remote := flags.Bool("remote", false, "show the Disco package with this ID instead of an installed Java")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	if *remote {
+		return a.remoteInfo(flags.Arg(0), *jsonOutput)
+	}
+
+	java, err := a.resolvePackage(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		out := infoJSON{packageJSON: newPackageJSON(java, a.isCurrent(java)), Signature: java.Signature}
+		if java.Release != nil {
+			out.Release = java.Release.Properties
+		}
+		return writeJSON(a.stdout, out)
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	writeField(w, "ID", java.ID)
+	writeField(w, "Distribution", java.Distribution)
+	writeField(w, "Version", java.JavaVersion)
+	writeField(w, "Package type", java.PackageType)
+	fmt.Fprintf(w, "JavaFX:\t%t\n", java.JavaFXBundled)
+	writeField(w, "Path", filepath.FromSlash(java.JavaDir))
+	writeField(w, "JAVA_HOME", filepath.FromSlash(java.JavaHome))
+	if java.Release != nil {
+		writeField(w, "Runtime version", java.Release.JavaRuntimeVersion)
+		writeField(w, "Implementor", java.Release.Implementor)
+		writeField(w, "Platform", joinNonEmpty(java.Release.OSName, java.Release.OSArch, java.Release.LibC))
+		fmt.Fprintf(w, "Modules:\t%d\n", len(java.Release.Modules))
+	}
+	if java.Signature != nil {
+		fmt.Fprintf(w, "Signature:\t%s %s\n", java.Signature.Status, java.Signature.Signer)
+	}
+	switch {
+	case java.Linked:
+		fmt.Fprintln(w, "Source:\tlinked")
+	case java.External:
+		fmt.Fprintln(w, "Source:\texternal")
+	}
+	return w.Flush()
+}
+
+// writeField prints a "Name: value" line of info, empty values are skipped
+func writeField(w io.Writer, name string, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "%s:\t%s\n", name, value)
+	}
+}
+
+func joinNonEmpty(values ...string) string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return strings.Join(result, " ")
+}
+
+func (a *app) remoteInfo(id string, jsonOutput bool) error {
+	pkg, err := a.vm.Client.GetPackage(a.ctx, id)
+	if err != nil {
+		return err
+	}
+	info, err := a.vm.Client.GetPackageInfo(a.ctx, id)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		return writeJSON(a.stdout, remoteInfoJSON{Package: pkg, Info: info})
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	writeField(w, "ID", pkg.ID)
+	writeField(w, "Distribution", pkg.Distribution)
+	writeField(w, "Version", pkg.JavaVersion)
+	writeField(w, "Release status", pkg.ReleaseStatus)
+	writeField(w, "Term of support", pkg.TermOfSupport)
+	writeField(w, "Package type", pkg.PackageType)
+	writeField(w, "Platform", joinNonEmpty(pkg.OperatingSystem, pkg.Architecture, pkg.LibCType))
+	writeField(w, "Filename", info.Filename)
+	fmt.Fprintf(w, "Size:\t%d\n", pkg.Size)
+	writeField(w, "Download", info.DirectDownloadURI)
+	fmt.Fprintf(w, "Checksum:\t%s %s\n", info.ChecksumType, info.Checksum)
+	writeField(w, "Signature", info.SignatureURI)
+	return w.Flush()
+}
+
+func runShims(a *app, args []string) error {
+	flags := a.newFlagSet("shims")
+	if err := parseFlags(flags, args, 0, 0); err != nil {
+		return err
+	}
+	if err := a.vm.GenerateShims(); err != nil {
+		return err
+	}
+	dir := filepath.FromSlash(a.vm.ShimsDir())
+	fmt.Fprintln(a.stdout, dir)
+	fmt.Fprintf(a.stderr, "Put %s first in PATH to use the shims\n", dir)
+	return nil
+}
+
+// runShimExec is invoked by the shims with the data directory they belong to,
+// the arguments are passed to the executable untouched
+func runShimExec(a *app, args []string) error {
+	if len(args) < 2 {
+		return errors.New("missing data directory or executable name")
+	}
+	vm, err := newVersionManager(args[0])
+	if err != nil {
+		return err
+	}
+	return vm.ShimExec(args[1], args[2:])
+}
@@ -0,0 +1,250 @@
+// Command jlib installs and selects Java versions from the command line.
+//
+// Usage:
+//
+//	jlib [-data-dir dir] [-disco-url url] <command> [flags] [args]
+//
+// Run "jlib help" for the list of commands.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/kunjude/jlib"
+)
+
+// Environment variable overriding the default data directory ~/.jlib
+const dataDirEnv = "JLIB_HOME"
+
+type command struct {
+	name   string
+	args   string // Synopsis of the arguments, shown by help
+	short  string // One line description, shown by help
+	hidden bool   // Not listed by help, e.g. shim-exec which is only invoked by the shims
+	run    func(a *app, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{name: "install", args: "[-archive file] [spec]", short: "Install a Java matching spec, or the one selected for the current directory", run: runInstall},
+		{name: "uninstall", args: "<id|spec>", short: "Remove an installed Java", run: runUninstall},
+		{name: "ls", args: "[-json] [-all]", short: "List the installed Javas", run: runLs},
+		{name: "ls-remote", args: "[-json] [spec]", short: "List the packages of the Disco API matching spec", run: runLsRemote},
+		{name: "use", args: "[-install] [-unset] [spec]", short: "Select a Java for the current directory and its subdirectories", run: runUse},
+		{name: "default", args: "[-install] [-unset] [spec]", short: "Show or set the global default Java", run: runDefault},
+		{name: "current", args: "[-json]", short: "Show the Java selected for the current directory and why", run: runCurrent},
+		{name: "which", args: "[name]", short: "Print the path of a binary of the current Java, java by default", run: runWhich},
+		{name: "env", args: "[-shell shell] [-unset] [spec]", short: "Print the shell commands activating a Java, for eval", run: runEnv},
+		{name: "exec", args: "[-install] [-J option]... <spec> <command> [args]", short: "Run a command under a Java matching spec", run: runExec},
+		{name: "info", args: "[-json] [-remote] <id|spec>", short: "Show the details of an installed Java or of a Disco package", run: runInfo},
+		{name: "shims", args: "", short: "Generate the shims and print the directory to put in PATH", run: runShims},
+		{name: jlib.ShimExecCommand, args: "<data-dir> <name> [args]", hidden: true, run: runShimExec},
+	}
+}
+
+// app holds the state shared by the commands
+type app struct {
+	vm     *jlib.VersionManager
+	ctx    context.Context
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// exitCodeError makes run exit with code without printing anything, e.g. the exit code of exec
+type exitCodeError struct {
+	code int
+}
+
+func (e *exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit code
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("jlib", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dataDir := flags.String("data-dir", os.Getenv(dataDirEnv), "directory where the Javas are installed, $"+dataDirEnv+" or ~/.jlib by default")
+	discoURL := flags.String("disco-url", "", "base URL of the Disco API")
+	flags.Usage = func() { usage(stderr) }
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if flags.NArg() == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	name, args := flags.Arg(0), flags.Args()[1:]
+	if name == "help" {
+		usage(stdout)
+		return 0
+	}
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "jlib: unknown command %q, run \"jlib help\"\n", name)
+		return 2
+	}
+
+	vm, err := newVersionManager(*dataDir)
+	if err != nil {
+		fmt.Fprintf(stderr, "jlib: %v\n", err)
+		return 1
+	}
+	if err := os.MkdirAll(vm.DataDir, 0755); err != nil {
+		fmt.Fprintf(stderr, "jlib: %v\n", err)
+		return 1
+	}
+	if *discoURL != "" {
+		vm.Client.BaseURL = *discoURL
+	}
+
+	a := &app{vm: vm, ctx: ctx, stdout: stdout, stderr: stderr}
+	err = cmd.run(a, args)
+	var exitErr *exitCodeError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	default:
+		fmt.Fprintf(stderr, "jlib %s: %v\n", name, err)
+		return 1
+	}
+}
+
+func newVersionManager(dataDir string) (*jlib.VersionManager, error) {
+	if dataDir == "" {
+		return jlib.NewDefaultVersionManager()
+	}
+	dataDir, err := filepath.Abs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return jlib.NewVersionManager(filepath.ToSlash(dataDir)), nil
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: jlib [-data-dir dir] [-disco-url url] <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		if !cmd.hidden {
+			fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.short)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `A spec is "[distribution@]version[,qualifiers]", e.g. 21, temurin@17, zulu@~11, >=17,lts or lts.`)
+	fmt.Fprintln(w, `Run "jlib <command> -h" for the flags of a command.`)
+}
+
+// newFlagSet returns the flag set of the command name, its usage prints the synopsis of the command
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	cmd := findCommand(name)
+	flags := flag.NewFlagSet("jlib "+name, flag.ContinueOnError)
+	flags.SetOutput(a.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: jlib %s %s\n", cmd.name, cmd.args)
+		if cmd.short != "" {
+			fmt.Fprintf(a.stderr, "\n%s.\n", cmd.short)
+		}
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the flags of a command and checks the number of remaining arguments
+func parseFlags(flags *flag.FlagSet, args []string, min int, max int) error {
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < min || (max >= 0 && flags.NArg() > max) {
+		flags.Usage()
+		return &exitCodeError{code: 2}
+	}
+	return nil
+}
+
+// resolvePackage finds an installed package by ID, or the newest one matching arg as a spec
+func (a *app) resolvePackage(arg string) (*jlib.JavaPackage, error) {
+	if java, err := a.vm.GetJavaByID(arg); err == nil {
+		return java, nil
+	}
+	spec, err := jlib.ParseJavaSpec(arg)
+	if err != nil {
+		return nil, err
+	}
+	java, err := a.vm.UseSpec(spec)
+	if errors.Is(err, jlib.ErrJavaNotFound) {
+		return nil, fmt.Errorf("%s is not installed", arg)
+	}
+	return java, err
+}
+
+// current resolves the Java of the working directory, failing with an explanation when it is not installed
+func (a *app) current() (*jlib.Selection, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	selection, err := a.vm.Current(wd)
+	if errors.Is(err, jlib.ErrJavaNotFound) {
+		if selection.Spec != nil {
+			return selection, fmt.Errorf("%s is not installed (%s), run \"jlib install\"", selection.Spec, selection.Explain())
+		}
+		return selection, errors.New(selection.Explain())
+	}
+	return selection, err
+}
+
+// exitCode returns the exit code of a command that ran and failed, or false if it could not run
+func exitCode(err error) (int, bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), true
+	}
+	return 0, false
+}
+
+// stringsFlag is a flag that can be repeated, e.g. -J -Xmx1g -J -ea
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, " ")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
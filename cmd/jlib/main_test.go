@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/kunjude/jlib"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeInstalledJDK creates a package directory in dataDir as the install of the Disco package id would
+func makeInstalledJDK(t *testing.T, dataDir string, id string, distribution string, javaVersion string) {
+	dir := filepath.Join(dataDir, id)
+	java := "java"
+	if runtime.GOOS == "windows" {
+		java += ".exe"
+	}
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "bin"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "bin", java), []byte("#!/bin/sh\n"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "release"), []byte(`JAVA_VERSION="`+javaVersion+`"`+"\n"), 0644))
+
+	meta, err := json.Marshal(jlib.PackageMetaInfo{
+		ID:           id,
+		Distribution: distribution,
+		JavaVersion:  javaVersion,
+		PackageType:  "jdk",
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "meta.json"), meta, 0644))
+}
+
+// runTest runs the command line with the data directory dataDir and returns the exit code and the outputs
+func runTest(dataDir string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append([]string{"-data-dir", dataDir}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestLs(t *testing.T) {
+	dataDir := t.TempDir()
+
+	code, stdout, _ := runTest(dataDir, "ls", "-json")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, "[]", stdout)
+
+	makeInstalledJDK(t, dataDir, "temurin-17", "temurin", "17.0.9")
+	makeInstalledJDK(t, dataDir, "zulu-21", "zulu", "21.0.1")
+	code, stdout, _ = runTest(dataDir, "ls", "-json")
+	assert.Equal(t, 0, code)
+	var list []packageJSON
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &list))
+	assert.Len(t, list, 2)
+	assert.Equal(t, "temurin-17", list[0].ID)
+	assert.Equal(t, "17.0.9", list[0].JavaVersion)
+	assert.Equal(t, filepath.Join(dataDir, "temurin-17"), list[0].Path)
+
+	code, stdout, _ = runTest(dataDir, "ls")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "zulu-21")
+}
+
+func TestDefaultAndCurrent(t *testing.T) {
+	dataDir := t.TempDir()
+	makeInstalledJDK(t, dataDir, "temurin-17", "temurin", "17.0.9")
+
+	code, _, stderr := runTest(dataDir, "default", "21")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "21 is not installed")
+
+	code, stdout, _ := runTest(dataDir, "default", "temurin@17")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Default set to temurin-17\n", stdout)
+
+	code, stdout, _ = runTest(dataDir, "default")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "temurin@17\n", stdout)
+
+	code, stdout, _ = runTest(dataDir, "current", "-json")
+	assert.Equal(t, 0, code)
+	var current currentJSON
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &current))
+	assert.Equal(t, jlib.SourceDefault, current.Source)
+	assert.True(t, current.Installed)
+	assert.Equal(t, "temurin-17", current.Java.ID)
+
+	code, stdout, _ = runTest(dataDir, "uninstall", "temurin-17")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Removed temurin-17\n", stdout)
+
+	code, stdout, _ = runTest(dataDir, "current", "-json")
+	assert.Equal(t, 1, code)
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &current))
+	assert.False(t, current.Installed)
+	assert.Equal(t, "temurin@17", current.Spec)
+}
+
+func TestLsRemote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jlib.DiscoResponseWrapper[[]jlib.GetPackagesResponse]{Result: []jlib.GetPackagesResponse{
+			{ID: "a", Distribution: "temurin", JavaVersion: "17.0.9+9", PackageType: "jdk"},
+			{ID: "b", Distribution: "temurin", JavaVersion: "21.0.1+12", PackageType: "jdk"},
+			{ID: "c", Distribution: "zulu", JavaVersion: "17.0.9+8", PackageType: "jdk"},
+		}})
+	}))
+	defer srv.Close()
+
+	code, stdout, _ := runTest(t.TempDir(), "-disco-url", srv.URL, "ls-remote", "-json", "temurin@17")
+	assert.Equal(t, 0, code)
+	var packages []jlib.GetPackagesResponse
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &packages))
+	assert.Len(t, packages, 1)
+	assert.Equal(t, "a", packages[0].ID)
+}
+
+func TestUnknownCommand(t *testing.T) {
+	code, _, stderr := runTest(t.TempDir(), "frobnicate")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "unknown command")
+}
+
+func TestShimExecDataDir(t *testing.T) {
+	// The shims name their data directory, whatever the -data-dir of the command line
+	dataDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dataDir, "state.json"), []byte(`{"default":"temurin@21"}`), 0644))
+
+	code, _, stderr := runTest(t.TempDir(), jlib.ShimExecCommand, dataDir, "java", "-version")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "temurin@21 is not installed")
+	assert.Contains(t, stderr, dataDir)
+
+	code, _, stderr = runTest(t.TempDir(), jlib.ShimExecCommand, "java")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "missing data directory")
+}
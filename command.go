@@ -0,0 +1,71 @@
+package jlib
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type CommandOptions struct {
+	Install         bool     // Install a package matching the spec when none is installed
+	JavaToolOptions []string // JVM options set in JAVA_TOOL_OPTIONS, picked up by every JVM the command starts
+}
+
+// Command returns a command running name with args under the newest installed package matching spec, see CommandContext
+func (vm *VersionManager) Command(spec *JavaSpec, name string, args []string, options ...*CommandOptions) (*exec.Cmd, error) {
+	return vm.CommandContext(context.Background(), spec, name, args, options...)
+}
+
+// CommandContext returns a command running name with args under the newest installed package matching spec.
+// JAVA_HOME is set and the bin directory of the package comes first in PATH, name is looked up there first
+// so that "java" or "jar" run the binaries of the package. The package is installed first when none matches
+// and Install is set, ctx bounds both the installation and the command.
+func (vm *VersionManager) CommandContext(ctx context.Context, spec *JavaSpec, name string, args []string, options ...*CommandOptions) (*exec.Cmd, error) {
+	opts := extractOptions(options)
+	if opts == nil {
+		opts = &CommandOptions{}
+	}
+
+	var java *JavaPackage
+	var err error
+	if opts.Install {
+		java, err = vm.UseOrInstallSpecContext(ctx, spec)
+		if IsInstalled(err) {
+			err = nil
+		}
+	} else {
+		java, err = vm.UseSpec(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	program := name
+	if !strings.ContainsAny(name, `/\`) {
+		bin := filepath.Join(filepath.FromSlash(java.JavaHome), "bin", name)
+		if file, err := exec.LookPath(bin); err == nil {
+			program = file
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, program, args...)
+	vars := Env(java)
+	if len(opts.JavaToolOptions) > 0 {
+		vars = append(vars, EnvVar{Name: "JAVA_TOOL_OPTIONS", Value: strings.Join(opts.JavaToolOptions, " ")})
+	}
+	cmd.Env = ApplyEnv(os.Environ(), vars)
+	return cmd, nil
+}
+
+// Exec runs name with args under the newest installed package matching spec, with the standard streams
+// of the current process. An *exec.ExitError is returned when the command fails.
+func (vm *VersionManager) Exec(spec *JavaSpec, name string, args []string, options ...*CommandOptions) error {
+	cmd, err := vm.Command(spec, name, args, options...)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,57 @@
+package jlib
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionManagerCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake java is a shell script")
+	}
+	vm := NewVersionManager(t.TempDir())
+	home := makeTestJDK(t, path.Join(t.TempDir(), "jdk-17"), testTemurinRelease)
+	script := "#!/bin/sh\necho \"$JAVA_HOME|$JAVA_TOOL_OPTIONS|$*\"\n"
+	assert.NoError(t, os.WriteFile(path.Join(home, "bin", "java"), []byte(script), 0755))
+	_, err := vm.Link("jdk-17", home)
+	assert.NoError(t, err)
+
+	spec, _ := ParseJavaSpec("temurin@17")
+	cmd, err := vm.Command(spec, "java", []string{"-version"}, &CommandOptions{JavaToolOptions: []string{"-Xmx1g", "-Dfile.encoding=UTF-8"}})
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(home, "bin", "java"), cmd.Path)
+	out, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, home+"|-Xmx1g -Dfile.encoding=UTF-8|-version\n", string(out))
+
+	// Other programs find the package first in PATH
+	cmd, err = vm.Command(spec, "sh", []string{"-c", "java build"})
+	assert.NoError(t, err)
+	out, err = cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, home+"||build\n", string(out))
+
+	spec, _ = ParseJavaSpec("zulu@21")
+	_, err = vm.Command(spec, "java", nil)
+	assert.ErrorIs(t, err, ErrJavaNotFound)
+}
+
+func TestVersionManagerCommandInstall(t *testing.T) {
+	srv := newTestDiscoServer(t, newTestJDKPackage(t, "zulu21", "zulu21-test", "zulu", 21))
+	vm := NewVersionManager(t.TempDir())
+	vm.Client = newTestDiscoClient(srv)
+
+	spec, _ := ParseJavaSpec("zulu@21")
+	cmd, err := vm.Command(spec, "java", nil, &CommandOptions{Install: true})
+	assert.NoError(t, err)
+	assert.Contains(t, cmd.Env, "JAVA_HOME="+filepath.FromSlash(path.Join(vm.DataDir, "zulu21-test")))
+	for _, kv := range cmd.Env {
+		assert.False(t, strings.HasPrefix(kv, "JAVA_TOOL_OPTIONS="))
+	}
+}
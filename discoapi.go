@@ -1,6 +1,7 @@
 package jlib
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,8 +15,39 @@ import (
 
 const DISCO_API_V3_BASE_URL = "https://api.foojay.io/disco/v3.0"
 
+// DefaultUserAgent is the User-Agent sent by clients created with NewDiscoClient
+const DefaultUserAgent = "jlib"
+
+// DiscoClient is a client for Foojay's Disco API.
+// Every endpoint is available as a method, the package-level functions use DefaultDiscoClient.
+type DiscoClient struct {
+	BaseURL      string                 // Base URL of the Disco API, e.g. DISCO_API_V3_BASE_URL or a mirror
+	HTTPClient   *http.Client           // HTTP client used for every request, http.DefaultClient if nil
+	UserAgent    string                 // User-Agent header value, not sent if empty
+	DefaultQuery map[string]interface{} // Query parameters sent with every request unless overridden by the call options
+	Retry        *RetryPolicy           // Policy for retrying transient failures, requests are not retried if nil
+	Cache        *DiscoCache            // Persistent cache of metadata responses, nothing is cached if nil
+}
+
+func NewDiscoClient(baseURL string) *DiscoClient {
+	return &DiscoClient{
+		BaseURL:    baseURL,
+		HTTPClient: http.DefaultClient,
+		UserAgent:  DefaultUserAgent,
+		Retry:      DefaultRetryPolicy(),
+	}
+}
+
+func NewDefaultDiscoClient() *DiscoClient {
+	return NewDiscoClient(DISCO_API_V3_BASE_URL)
+}
+
+// DefaultDiscoClient is used by the package-level Disco API functions
+var DefaultDiscoClient = NewDefaultDiscoClient()
+
 type DiscoResponseWrapper[T any] struct {
-	Result T `json:"result"`
+	Result  T      `json:"result"`
+	Message string `json:"message"`
 }
 
 type DiscoApiEndpoint struct {
@@ -33,14 +65,50 @@ func structToMap[T any](s T) (data map[string]interface{}, err error) {
 	return data, mapstructure.Decode(s, &data)
 }
 
-func getAndParseResponseWithQuery[TResponse any](base string, query map[string]interface{}, path ...string) (TResponse, error) {
-	u, err := url.JoinPath(base, path...)
+func (c *DiscoClient) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *DiscoClient) get(ctx context.Context, u string) (*http.Response, error) {
+	return c.getWithHeader(ctx, u, nil)
+}
+
+func (c *DiscoClient) getWithHeader(ctx context.Context, u string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
-		return *new(TResponse), err
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	return c.Retry.doWithRetry(ctx, func() (*http.Response, error) {
+		return c.httpClient().Do(req)
+	})
+}
+
+// buildURL joins the path to the base URL and encodes the query merged over DefaultQuery
+func (c *DiscoClient) buildURL(query map[string]interface{}, path ...string) (string, error) {
+	u, err := url.JoinPath(c.BaseURL, path...)
+	if err != nil {
+		return "", err
 	}
 	q := url.Values{}
 
+	merged := make(map[string]interface{}, len(c.DefaultQuery)+len(query))
+	for k, v := range c.DefaultQuery {
+		merged[k] = v
+	}
 	for k, v := range query {
+		merged[k] = v
+	}
+
+	for k, v := range merged {
 		// Make it possible to pass arrays as query parameters
 		switch v := v.(type) {
 		case []string:
@@ -59,15 +127,26 @@ func getAndParseResponseWithQuery[TResponse any](base string, query map[string]i
 	if len(q) > 0 {
 		u += "?" + q.Encode()
 	}
+	return u, nil
+}
 
-	resp, err := http.Get(u)
+func getAndParseResponseWithQuery[TResponse any](ctx context.Context, c *DiscoClient, query map[string]interface{}, path ...string) (TResponse, error) {
+	u, err := c.buildURL(query, path...)
+	if err != nil {
+		return *new(TResponse), err
+	}
+
+	endpoint := ""
+	if len(path) > 0 {
+		endpoint = path[0]
+	}
+	body, err := c.fetch(ctx, endpoint, u)
 	if err != nil {
 		return *new(TResponse), err
 	}
-	defer resp.Body.Close()
 
 	var wrapper DiscoResponseWrapper[TResponse]
-	err = json.NewDecoder(resp.Body).Decode(&wrapper)
+	err = json.Unmarshal(body, &wrapper)
 	if err != nil {
 		return *new(TResponse), err
 	}
@@ -75,12 +154,12 @@ func getAndParseResponseWithQuery[TResponse any](base string, query map[string]i
 	return wrapper.Result, nil
 }
 
-func getAndParseResponse[TResponse any](base string, path ...string) (TResponse, error) {
-	return getAndParseResponseWithQuery[TResponse](base, map[string]interface{}{}, path...)
+func getAndParseResponse[TResponse any](ctx context.Context, c *DiscoClient, path ...string) (TResponse, error) {
+	return getAndParseResponseWithQuery[TResponse](ctx, c, map[string]interface{}{}, path...)
 }
 
-func GetDiscoApiEndpoints() ([]DiscoApiEndpoint, error) {
-	r, err := getAndParseResponse[[]DiscoApiEndpoint](DISCO_API_V3_BASE_URL)
+func (c *DiscoClient) GetDiscoApiEndpoints(ctx context.Context) ([]DiscoApiEndpoint, error) {
+	r, err := getAndParseResponse[[]DiscoApiEndpoint](ctx, c)
 	return r, err
 }
 
@@ -103,13 +182,13 @@ type DistributionsResponse struct {
 }
 
 // Returns a list of all supported distributions
-func GetDistributions(options ...*DistributionsOptions) ([]DistributionsResponse, error) {
+func (c *DiscoClient) GetDistributions(ctx context.Context, options ...*DistributionsOptions) ([]DistributionsResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := getAndParseResponseWithQuery[[]DistributionsResponse](DISCO_API_V3_BASE_URL, query, "distributions")
+	r, err := getAndParseResponseWithQuery[[]DistributionsResponse](ctx, c, query, "distributions")
 	return r, err
 }
 
@@ -121,13 +200,13 @@ type DistributionsForGivenVersionOptions struct {
 }
 
 // Returns a list of all distributions that support the given Java version
-func GetDistributionsForGivenVersion(version string, options ...*DistributionsForGivenVersionOptions) ([]DistributionsResponse, error) {
+func (c *DiscoClient) GetDistributionsForGivenVersion(ctx context.Context, version string, options ...*DistributionsForGivenVersionOptions) ([]DistributionsResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := getAndParseResponseWithQuery[[]DistributionsResponse](DISCO_API_V3_BASE_URL, query, "distributions", "versions", version)
+	r, err := getAndParseResponseWithQuery[[]DistributionsResponse](ctx, c, query, "distributions", "versions", version)
 	return r, err
 }
 
@@ -141,43 +220,100 @@ type GetDistributionOptions struct {
 }
 
 // Returns detailled information about a given distribution
-func GetDistribution(distribution string, options ...*GetDistributionOptions) ([]DistributionsResponse, error) {
+func (c *DiscoClient) GetDistribution(ctx context.Context, distribution string, options ...*GetDistributionOptions) ([]DistributionsResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := getAndParseResponseWithQuery[[]DistributionsResponse](DISCO_API_V3_BASE_URL, query, "distributions", distribution)
+	r, err := getAndParseResponseWithQuery[[]DistributionsResponse](ctx, c, query, "distributions", distribution)
 	return r, err
 }
 
 // Redirects to either the direct download link or the download site of the requested package defined by it's id
-func GetPackageRedirect(id string) (string, error) {
-	u, err := url.JoinPath(DISCO_API_V3_BASE_URL, "ids", id, "redirect")
+func (c *DiscoClient) GetPackageRedirect(ctx context.Context, id string) (string, error) {
+	u, err := url.JoinPath(c.BaseURL, "ids", id, "redirect")
 	if err != nil {
 		return "", err
 	}
 
-	resp, err := http.Get(u)
+	return c.resolveRedirect(ctx, "ids", u)
+}
+
+// followRedirect returns the final URL of a GET of u. Only the final URL is needed, the body is left unread.
+func (c *DiscoClient) followRedirect(ctx context.Context, u string) (string, error) {
+	resp, err := c.get(ctx, u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
 
-	return resp.Request.URL.String(), err
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+	return resp.Request.URL.String(), nil
 }
 
-func GetFilename(id string) (string, error) {
-	finalURL, err := GetPackageRedirect(id)
+// GetFilename returns the filename of the archive of the package defined by the given id
+func (c *DiscoClient) GetFilename(ctx context.Context, id string) (string, error) {
+	info, err := c.GetPackageInfo(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if info.Filename != "" {
+		return info.Filename, nil
+	}
+
+	finalURL, err := c.GetPackageRedirect(ctx, id)
 	if err != nil {
 		return "", err
 	}
 	return path.Base(finalURL), nil
 }
 
-// DownloadJavaByID downloads Java archive by its ID to dest directory and returns the filename
-func DownloadJavaByID(id string, dst string) (*os.File, error) {
-	javaUrl, err := GetPackageRedirect(id)
+type PackageInfo struct {
+	Filename          string `json:"filename"`
+	DirectDownloadURI string `json:"direct_download_uri"`
+	DownloadSiteURI   string `json:"download_site_uri"`
+	SignatureURI      string `json:"signature_uri"`
+	ChecksumURI       string `json:"checksum_uri"`
+	Checksum          string `json:"checksum"`
+	ChecksumType      string `json:"checksum_type"`
+}
+
+// Returns the download and verification links of a package defined by the given package id
+func (c *DiscoClient) GetPackageInfo(ctx context.Context, id string) (*PackageInfo, error) {
+	res, err := getAndParseResponse[[]PackageInfo](ctx, c, "ids", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no package info found for id %v: %w", id, ErrDiscoNotFound)
+	}
+	return &res[0], nil
+}
+
+// DownloadJavaByID downloads Java archive by its ID to dest directory and returns the filename.
+// The direct download URI of the package is used when available, otherwise the redirect is followed.
+// The archive is verified against the checksum published for the package, a mismatch is reported as *ChecksumMismatchError.
+func (c *DiscoClient) DownloadJavaByID(ctx context.Context, id string, dst string) (*os.File, error) {
+	info, err := c.GetPackageInfo(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	return DownloadFile(javaUrl, dst)
+
+	javaUrl := info.DirectDownloadURI
+	if javaUrl == "" {
+		if javaUrl, err = c.GetPackageRedirect(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	checksum, err := c.packageChecksum(ctx, info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checksum: %w", err)
+	}
+	return c.downloadFile(ctx, javaUrl, dst, info.Filename, checksum)
 }
 
 type GetAllMajorVersionsOptions struct {
@@ -200,13 +336,13 @@ type GetAllMajorVersionsResponse struct {
 }
 
 // Return a list of major versions defined by the given parameters
-func GetAllMajorVersions(options ...*GetAllMajorVersionsOptions) ([]GetAllMajorVersionsResponse, error) {
+func (c *DiscoClient) GetAllMajorVersions(ctx context.Context, options ...*GetAllMajorVersionsOptions) ([]GetAllMajorVersionsResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	r, err := getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](DISCO_API_V3_BASE_URL, query, "major_versions")
+	r, err := getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](ctx, c, query, "major_versions")
 	return r, err
 }
 
@@ -216,34 +352,34 @@ type BuildAndVersionOptions struct {
 }
 
 // Returns the specified major version including early access builds
-func GetSpecificMajorVersionIncludingEA(version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
+func (c *DiscoClient) GetSpecificMajorVersionIncludingEA(ctx context.Context, version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](DISCO_API_V3_BASE_URL, query, "major_versions", fmt.Sprintf("%v", version), "ea")
+	return getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](ctx, c, query, "major_versions", fmt.Sprintf("%v", version), "ea")
 }
 
 // Returns the specified major version excluding early access builds
-func GetSpecificMajorVersion(version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
+func (c *DiscoClient) GetSpecificMajorVersion(ctx context.Context, version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
 	opt := extractOptions(options)
 	query, err := structToMap(opt)
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](DISCO_API_V3_BASE_URL, query, "major_versions", fmt.Sprintf("%v", version), "ga")
+	return getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](ctx, c, query, "major_versions", fmt.Sprintf("%v", version), "ga")
 }
 
 // Returns information about the requested major version
-func GetMajorVersion(version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
+func (c *DiscoClient) GetMajorVersion(ctx context.Context, version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](DISCO_API_V3_BASE_URL, query, "major_versions", fmt.Sprintf("%v", version))
+	return getAndParseResponseWithQuery[[]GetAllMajorVersionsResponse](ctx, c, query, "major_versions", fmt.Sprintf("%v", version))
 }
 
 type GetMajorVersionsNewOptions struct {
@@ -264,13 +400,13 @@ type GetMajorVersionsNewResponse struct {
 }
 
 // Return a list of major versions defined by the given parameters
-func GetMajorVersionsNew(options ...*GetMajorVersionsNewOptions) ([]GetMajorVersionsNewResponse, error) {
+func (c *DiscoClient) GetMajorVersionsNew(ctx context.Context, options ...*GetMajorVersionsNewOptions) ([]GetMajorVersionsNewResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetMajorVersionsNewResponse](DISCO_API_V3_BASE_URL, query, "major_versions")
+	return getAndParseResponseWithQuery[[]GetMajorVersionsNewResponse](ctx, c, query, "major_versions")
 }
 
 type GetPackagesResponseFeature = GetSupportedArchiveTypesResponse
@@ -339,13 +475,13 @@ type GetPackagesResponse struct {
 
 // Returns a list of packages defined by the given parameters.
 // The version parameter not only supports different formats for version numbers (e.g. 11.9.0.1, 1.8.0_262, 15, 16-ea) but also ranges (e.g. 15.0.1..<16). The ranges are defined as follows: VersionNumber1...VersionNumber2 => includes VersionNumber1 and VersionNumber2 VersionNumber1.. includes VersionNumber1 and excludes VersionNumber2 VersionNumber1>..VersionNUmber2 => excludes VersionNumber1 and includes VersionNumber2 VersionNumber1>. excludes VersionNumber1 and VersionNumber2
-func GetPackages(options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
+func (c *DiscoClient) GetPackages(ctx context.Context, options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetPackagesResponse](DISCO_API_V3_BASE_URL, query, "packages")
+	return getAndParseResponseWithQuery[[]GetPackagesResponse](ctx, c, query, "packages")
 }
 
 type GetAllPackagesOptions struct {
@@ -354,23 +490,23 @@ type GetAllPackagesOptions struct {
 }
 
 // Returns all packages defined the downloadable and include_ea parameter
-func GetAllPackages(options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
+func (c *DiscoClient) GetAllPackages(ctx context.Context, options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetPackagesResponse](DISCO_API_V3_BASE_URL, query, "packages")
+	return getAndParseResponseWithQuery[[]GetPackagesResponse](ctx, c, query, "packages")
 }
 
 // Returns all packages that are builds of GraalVM
-func GetAllPackagesGraalVM(options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
+func (c *DiscoClient) GetAllPackagesGraalVM(ctx context.Context, options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetPackagesResponse](DISCO_API_V3_BASE_URL, query, "packages", "all_builds_of_graalvm")
+	return getAndParseResponseWithQuery[[]GetPackagesResponse](ctx, c, query, "packages", "all_builds_of_graalvm")
 }
 
 type AllPackagesOpenJDKOptions struct {
@@ -379,43 +515,43 @@ type AllPackagesOpenJDKOptions struct {
 }
 
 // Returns all packages that are builds of OpenJDK
-func GetAllPackagesOpenJDK(options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
+func (c *DiscoClient) GetAllPackagesOpenJDK(ctx context.Context, options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetPackagesResponse](DISCO_API_V3_BASE_URL, query, "packages", "all_builds_of_openjdk")
+	return getAndParseResponseWithQuery[[]GetPackagesResponse](ctx, c, query, "packages", "all_builds_of_openjdk")
 }
 
 // Returns a list of packages that are of package_type JDK defined by the given parameters. The version parameter not only supports different formats for version numbers (e.g. 11.9.0.1, 1.8.0_262, 15, 16-ea) but also ranges (e.g. 15.0.1..<16). The ranges are defined as follows: VersionNumber1...VersionNumber2 => includes VersionNumber1 and VersionNumber2 VersionNumber1.. includes VersionNumber1 and excludes VersionNumber2 VersionNumber1>..VersionNUmber2 => excludes VersionNumber1 and includes VersionNumber2 VersionNumber1>. excludes VersionNumber1 and VersionNumber2
-func GetJDKPackages(options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
+func (c *DiscoClient) GetJDKPackages(ctx context.Context, options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetPackagesResponse](DISCO_API_V3_BASE_URL, query, "packages", "jdks")
+	return getAndParseResponseWithQuery[[]GetPackagesResponse](ctx, c, query, "packages", "jdks")
 }
 
 // Returns a list of packages that are of package_type JRE defined by the given parameters. The version parameter not only supports different formats for version numbers (e.g. 11.9.0.1, 1.8.0_262, 15, 16-ea) but also ranges (e.g. 15.0.1..<16). The ranges are defined as follows: VersionNumber1...VersionNumber2 => includes VersionNumber1 and VersionNumber2 VersionNumber1.. includes VersionNumber1 and excludes VersionNumber2 VersionNumber1>..VersionNUmber2 => excludes VersionNumber1 and includes VersionNumber2 VersionNumber1>. excludes VersionNumber1 and VersionNumber2
-func GetJREPackages(options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
+func (c *DiscoClient) GetJREPackages(ctx context.Context, options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
 	query, err := structToMap(extractOptions(options))
 	if err != nil {
 		return nil, err
 	}
 
-	return getAndParseResponseWithQuery[[]GetPackagesResponse](DISCO_API_V3_BASE_URL, query, "packages", "jres")
+	return getAndParseResponseWithQuery[[]GetPackagesResponse](ctx, c, query, "packages", "jres")
 }
 
 // Returns information about a package defined by the given package id
-func GetPackage(id string) (GetPackagesResponse, error) {
-	res, err := getAndParseResponse[[]GetPackagesResponse](DISCO_API_V3_BASE_URL, "packages", id)
+func (c *DiscoClient) GetPackage(ctx context.Context, id string) (GetPackagesResponse, error) {
+	res, err := getAndParseResponse[[]GetPackagesResponse](ctx, c, "packages", id)
 	if err != nil {
 		return GetPackagesResponse{}, err
 	}
 	if len(res) == 0 {
-		return GetPackagesResponse{}, fmt.Errorf("no package found for id %v", id)
+		return GetPackagesResponse{}, fmt.Errorf("no package found for id %v: %w", id, ErrDiscoNotFound)
 	}
 	return res[0], err
 }
@@ -457,8 +593,8 @@ type ParametersV3 struct {
 	} `json:"ids"`
 }
 
-func GetParameters() (*ParametersV3, error) {
-	p, err := getAndParseResponse[[]ParametersV3](DISCO_API_V3_BASE_URL, "parameters")
+func (c *DiscoClient) GetParameters(ctx context.Context) (*ParametersV3, error) {
+	p, err := getAndParseResponse[[]ParametersV3](ctx, c, "parameters")
 	if err != nil {
 		return nil, err
 	}
@@ -474,8 +610,8 @@ type RemainingDaysToNextReleaseResponse struct {
 }
 
 // Returns the remaining days to next feature release (e.g. 21 GA) based on the current release cadence
-func GetRemainingDaysToNextRelease() (*RemainingDaysToNextReleaseResponse, error) {
-	r, err := getAndParseResponse[[]RemainingDaysToNextReleaseResponse](DISCO_API_V3_BASE_URL, "remaining_days", "release")
+func (c *DiscoClient) GetRemainingDaysToNextRelease(ctx context.Context) (*RemainingDaysToNextReleaseResponse, error) {
+	r, err := getAndParseResponse[[]RemainingDaysToNextReleaseResponse](ctx, c, "remaining_days", "release")
 	if err != nil {
 		return nil, err
 	}
@@ -490,8 +626,8 @@ type GetRemainingDaysToNextUpdateReponse struct {
 	DateOfNextUpdate string `json:"date_of_next_update"`
 }
 
-func GetRemainingDaysToNextUpdate() (*GetRemainingDaysToNextUpdateReponse, error) {
-	r, err := getAndParseResponse[[]GetRemainingDaysToNextUpdateReponse](DISCO_API_V3_BASE_URL, "remaining_days", "update")
+func (c *DiscoClient) GetRemainingDaysToNextUpdate(ctx context.Context) (*GetRemainingDaysToNextUpdateReponse, error) {
+	r, err := getAndParseResponse[[]GetRemainingDaysToNextUpdateReponse](ctx, c, "remaining_days", "update")
 	if err != nil {
 		return nil, err
 	}
@@ -508,8 +644,8 @@ type GetSupportedArchitecturesResponse struct {
 	Bitness   string `json:"bitness"`
 }
 
-func GetSupportedArchitectures() ([]GetSupportedArchitecturesResponse, error) {
-	return getAndParseResponse[[]GetSupportedArchitecturesResponse](DISCO_API_V3_BASE_URL, "supported_architectures")
+func (c *DiscoClient) GetSupportedArchitectures(ctx context.Context) ([]GetSupportedArchitecturesResponse, error) {
+	return getAndParseResponse[[]GetSupportedArchitecturesResponse](ctx, c, "supported_architectures")
 }
 
 type GetSupportedArchiveTypesResponse struct {
@@ -518,32 +654,32 @@ type GetSupportedArchiveTypesResponse struct {
 	ApiString string `json:"api_string"`
 }
 
-func GetSupportedArchiveTypes() ([]GetSupportedArchiveTypesResponse, error) {
-	return getAndParseResponse[[]GetSupportedArchiveTypesResponse](DISCO_API_V3_BASE_URL, "supported_archive_types")
+func (c *DiscoClient) GetSupportedArchiveTypes(ctx context.Context) ([]GetSupportedArchiveTypesResponse, error) {
+	return getAndParseResponse[[]GetSupportedArchiveTypesResponse](ctx, c, "supported_archive_types")
 }
 
 type GetSupportedFeaturesResponse = GetSupportedArchiveTypesResponse
 
-func GetSupportedFeatures() ([]GetSupportedFeaturesResponse, error) {
-	return getAndParseResponse[[]GetSupportedFeaturesResponse](DISCO_API_V3_BASE_URL, "supported_features")
+func (c *DiscoClient) GetSupportedFeatures(ctx context.Context) ([]GetSupportedFeaturesResponse, error) {
+	return getAndParseResponse[[]GetSupportedFeaturesResponse](ctx, c, "supported_features")
 }
 
 type GetSupportedFPUsResponse = GetSupportedArchiveTypesResponse
 
-func GetSupportedFPUs() ([]GetSupportedFPUsResponse, error) {
-	return getAndParseResponse[[]GetSupportedFPUsResponse](DISCO_API_V3_BASE_URL, "supported_fpus")
+func (c *DiscoClient) GetSupportedFPUs(ctx context.Context) ([]GetSupportedFPUsResponse, error) {
+	return getAndParseResponse[[]GetSupportedFPUsResponse](ctx, c, "supported_fpus")
 }
 
 type GetSupportedLatestParametersResponse = GetSupportedArchiveTypesResponse
 
-func GetSupportedLatestParameters() ([]GetSupportedLatestParametersResponse, error) {
-	return getAndParseResponse[[]GetSupportedLatestParametersResponse](DISCO_API_V3_BASE_URL, "supported_latest_parameters")
+func (c *DiscoClient) GetSupportedLatestParameters(ctx context.Context) ([]GetSupportedLatestParametersResponse, error) {
+	return getAndParseResponse[[]GetSupportedLatestParametersResponse](ctx, c, "supported_latest_parameters")
 }
 
 type GetSupportedLibCTypesResponse = GetSupportedArchiveTypesResponse
 
-func GetSupportedLibCTypes() ([]GetSupportedLibCTypesResponse, error) {
-	return getAndParseResponse[[]GetSupportedLibCTypesResponse](DISCO_API_V3_BASE_URL, "supported_lib_c_types")
+func (c *DiscoClient) GetSupportedLibCTypes(ctx context.Context) ([]GetSupportedLibCTypesResponse, error) {
+	return getAndParseResponse[[]GetSupportedLibCTypesResponse](ctx, c, "supported_lib_c_types")
 }
 
 type GetSupportedOperatingSystemsResponse struct {
@@ -553,24 +689,24 @@ type GetSupportedOperatingSystemsResponse struct {
 	LibCType  string `json:"lib_c_type"`
 }
 
-func GetSupportedOperatingSystems() ([]GetSupportedOperatingSystemsResponse, error) {
-	return getAndParseResponse[[]GetSupportedOperatingSystemsResponse](DISCO_API_V3_BASE_URL, "supported_operating_systems")
+func (c *DiscoClient) GetSupportedOperatingSystems(ctx context.Context) ([]GetSupportedOperatingSystemsResponse, error) {
+	return getAndParseResponse[[]GetSupportedOperatingSystemsResponse](ctx, c, "supported_operating_systems")
 }
 
 type GetSupportedPackageTypesResponse = GetSupportedArchiveTypesResponse
 
-func GetSupportedPackageTypes() ([]GetSupportedPackageTypesResponse, error) {
-	return getAndParseResponse[[]GetSupportedPackageTypesResponse](DISCO_API_V3_BASE_URL, "supported_package_types")
+func (c *DiscoClient) GetSupportedPackageTypes(ctx context.Context) ([]GetSupportedPackageTypesResponse, error) {
+	return getAndParseResponse[[]GetSupportedPackageTypesResponse](ctx, c, "supported_package_types")
 }
 
 type GetSupportedReleaseStatusResponse = GetSupportedArchiveTypesResponse
 
-func GetSupportedReleaseStatus() ([]GetSupportedReleaseStatusResponse, error) {
-	return getAndParseResponse[[]GetSupportedReleaseStatusResponse](DISCO_API_V3_BASE_URL, "supported_release_status")
+func (c *DiscoClient) GetSupportedReleaseStatus(ctx context.Context) ([]GetSupportedReleaseStatusResponse, error) {
+	return getAndParseResponse[[]GetSupportedReleaseStatusResponse](ctx, c, "supported_release_status")
 }
 
 type GetSupportedTermsOfSupportResponse = GetSupportedArchiveTypesResponse
 
-func GetSupportedTermsOfSupport() ([]GetSupportedTermsOfSupportResponse, error) {
-	return getAndParseResponse[[]GetSupportedTermsOfSupportResponse](DISCO_API_V3_BASE_URL, "supported_terms_of_support")
+func (c *DiscoClient) GetSupportedTermsOfSupport(ctx context.Context) ([]GetSupportedTermsOfSupportResponse, error) {
+	return getAndParseResponse[[]GetSupportedTermsOfSupportResponse](ctx, c, "supported_terms_of_support")
 }
@@ -0,0 +1,149 @@
+package jlib
+
+import (
+	"context"
+	"os"
+)
+
+// Package-level shortcuts for the Disco API, every function calls the method of the same name on DefaultDiscoClient
+// with context.Background(). Use the DiscoClient methods directly to pass a context.
+
+func GetDiscoApiEndpoints() ([]DiscoApiEndpoint, error) {
+	return DefaultDiscoClient.GetDiscoApiEndpoints(context.Background())
+}
+
+func GetDistributions(options ...*DistributionsOptions) ([]DistributionsResponse, error) {
+	return DefaultDiscoClient.GetDistributions(context.Background(), options...)
+}
+
+func GetDistributionsForGivenVersion(version string, options ...*DistributionsForGivenVersionOptions) ([]DistributionsResponse, error) {
+	return DefaultDiscoClient.GetDistributionsForGivenVersion(context.Background(), version, options...)
+}
+
+func GetDistribution(distribution string, options ...*GetDistributionOptions) ([]DistributionsResponse, error) {
+	return DefaultDiscoClient.GetDistribution(context.Background(), distribution, options...)
+}
+
+func GetPackageRedirect(id string) (string, error) {
+	return DefaultDiscoClient.GetPackageRedirect(context.Background(), id)
+}
+
+func GetFilename(id string) (string, error) {
+	return DefaultDiscoClient.GetFilename(context.Background(), id)
+}
+
+func DownloadJavaByID(id string, dst string) (*os.File, error) {
+	return DefaultDiscoClient.DownloadJavaByID(context.Background(), id, dst)
+}
+
+func GetAllMajorVersions(options ...*GetAllMajorVersionsOptions) ([]GetAllMajorVersionsResponse, error) {
+	return DefaultDiscoClient.GetAllMajorVersions(context.Background(), options...)
+}
+
+func GetSpecificMajorVersionIncludingEA(version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
+	return DefaultDiscoClient.GetSpecificMajorVersionIncludingEA(context.Background(), version, options...)
+}
+
+func GetSpecificMajorVersion(version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
+	return DefaultDiscoClient.GetSpecificMajorVersion(context.Background(), version, options...)
+}
+
+func GetMajorVersion(version int, options ...*BuildAndVersionOptions) ([]GetAllMajorVersionsResponse, error) {
+	return DefaultDiscoClient.GetMajorVersion(context.Background(), version, options...)
+}
+
+func GetMajorVersionsNew(options ...*GetMajorVersionsNewOptions) ([]GetMajorVersionsNewResponse, error) {
+	return DefaultDiscoClient.GetMajorVersionsNew(context.Background(), options...)
+}
+
+func GetPackages(options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
+	return DefaultDiscoClient.GetPackages(context.Background(), options...)
+}
+
+func GetAllPackages(options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
+	return DefaultDiscoClient.GetAllPackages(context.Background(), options...)
+}
+
+func GetAllPackagesGraalVM(options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
+	return DefaultDiscoClient.GetAllPackagesGraalVM(context.Background(), options...)
+}
+
+func GetAllPackagesOpenJDK(options ...*GetAllPackagesOptions) ([]GetPackagesResponse, error) {
+	return DefaultDiscoClient.GetAllPackagesOpenJDK(context.Background(), options...)
+}
+
+func GetJDKPackages(options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
+	return DefaultDiscoClient.GetJDKPackages(context.Background(), options...)
+}
+
+func GetJREPackages(options ...*GetPackagesOptions) ([]GetPackagesResponse, error) {
+	return DefaultDiscoClient.GetJREPackages(context.Background(), options...)
+}
+
+func GetPackage(id string) (GetPackagesResponse, error) {
+	return DefaultDiscoClient.GetPackage(context.Background(), id)
+}
+
+func GetParameters() (*ParametersV3, error) {
+	return DefaultDiscoClient.GetParameters(context.Background())
+}
+
+func GetRemainingDaysToNextRelease() (*RemainingDaysToNextReleaseResponse, error) {
+	return DefaultDiscoClient.GetRemainingDaysToNextRelease(context.Background())
+}
+
+func GetRemainingDaysToNextUpdate() (*GetRemainingDaysToNextUpdateReponse, error) {
+	return DefaultDiscoClient.GetRemainingDaysToNextUpdate(context.Background())
+}
+
+func GetSupportedArchitectures() ([]GetSupportedArchitecturesResponse, error) {
+	return DefaultDiscoClient.GetSupportedArchitectures(context.Background())
+}
+
+func GetSupportedArchiveTypes() ([]GetSupportedArchiveTypesResponse, error) {
+	return DefaultDiscoClient.GetSupportedArchiveTypes(context.Background())
+}
+
+func GetSupportedFeatures() ([]GetSupportedFeaturesResponse, error) {
+	return DefaultDiscoClient.GetSupportedFeatures(context.Background())
+}
+
+func GetSupportedFPUs() ([]GetSupportedFPUsResponse, error) {
+	return DefaultDiscoClient.GetSupportedFPUs(context.Background())
+}
+
+func GetSupportedLatestParameters() ([]GetSupportedLatestParametersResponse, error) {
+	return DefaultDiscoClient.GetSupportedLatestParameters(context.Background())
+}
+
+func GetSupportedLibCTypes() ([]GetSupportedLibCTypesResponse, error) {
+	return DefaultDiscoClient.GetSupportedLibCTypes(context.Background())
+}
+
+func GetSupportedOperatingSystems() ([]GetSupportedOperatingSystemsResponse, error) {
+	return DefaultDiscoClient.GetSupportedOperatingSystems(context.Background())
+}
+
+func GetSupportedPackageTypes() ([]GetSupportedPackageTypesResponse, error) {
+	return DefaultDiscoClient.GetSupportedPackageTypes(context.Background())
+}
+
+func GetSupportedReleaseStatus() ([]GetSupportedReleaseStatusResponse, error) {
+	return DefaultDiscoClient.GetSupportedReleaseStatus(context.Background())
+}
+
+func GetSupportedTermsOfSupport() ([]GetSupportedTermsOfSupportResponse, error) {
+	return DefaultDiscoClient.GetSupportedTermsOfSupport(context.Background())
+}
+
+func GetPackageInfo(id string) (*PackageInfo, error) {
+	return DefaultDiscoClient.GetPackageInfo(context.Background(), id)
+}
+
+func GetPackageChecksum(id string) (*Checksum, error) {
+	return DefaultDiscoClient.GetPackageChecksum(context.Background(), id)
+}
+
+func DownloadFile(url string, dest string) (*os.File, error) {
+	return DefaultDiscoClient.DownloadFile(context.Background(), url, dest)
+}
@@ -0,0 +1,82 @@
+package jlib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrDiscoNotFound    = fmt.Errorf("disco api: not found")
+	ErrDiscoRateLimited = fmt.Errorf("disco api: rate limited")
+)
+
+// maxErrorBodySize limits how much of an error response is read to extract the message
+const maxErrorBodySize = 64 << 10
+
+// DiscoAPIError is returned when the Disco API (or a download it redirects to) answers with a non-2xx status
+type DiscoAPIError struct {
+	StatusCode int    // HTTP status code of the response
+	Endpoint   string // Requested URL without the query
+	Query      string // Encoded query of the request, empty if there was none
+	Message    string // Message reported by the API, or the status text if the body had none
+}
+
+func (e *DiscoAPIError) Error() string {
+	u := e.Endpoint
+	if e.Query != "" {
+		u += "?" + e.Query
+	}
+	return fmt.Sprintf("disco api: %s returned %d: %s", u, e.StatusCode, e.Message)
+}
+
+// Is makes the error match ErrDiscoNotFound and ErrDiscoRateLimited with errors.Is
+func (e *DiscoAPIError) Is(target error) bool {
+	switch target {
+	case ErrDiscoNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrDiscoRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// IsNotFound reports whether err is caused by a missing Disco API resource
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrDiscoNotFound)
+}
+
+// IsRateLimited reports whether err is caused by the Disco API rate limit
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrDiscoRateLimited)
+}
+
+// checkResponse returns a *DiscoAPIError for non-2xx responses. The body is consumed in that case.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	apiErr := &DiscoAPIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		u := *resp.Request.URL
+		apiErr.Query = u.RawQuery
+		u.RawQuery = ""
+		apiErr.Endpoint = u.String()
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	var wrapper struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &wrapper) == nil && wrapper.Message != "" {
+		apiErr.Message = wrapper.Message
+	}
+
+	return apiErr
+}
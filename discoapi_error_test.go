@@ -0,0 +1,53 @@
+package jlib
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoAPIError(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"result":[],"message":"distribution unknown not found"}`))
+	}))
+	defer srv.Close()
+	c := NewDiscoClient(srv.URL)
+	c.Retry = nil
+
+	t.Run("NotFound", func(t *testing.T) {
+		status = http.StatusNotFound
+		_, err := c.GetDistribution(context.Background(), "unknown", &GetDistributionOptions{IncludeEA: true})
+		assert.True(t, IsNotFound(err))
+		assert.False(t, IsRateLimited(err))
+
+		var apiErr *DiscoAPIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+		assert.Equal(t, srv.URL+"/distributions/unknown", apiErr.Endpoint)
+		assert.Equal(t, "include_ea=true", apiErr.Query)
+		assert.Equal(t, "distribution unknown not found", apiErr.Message)
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		status = http.StatusTooManyRequests
+		_, err := c.GetPackages(context.Background())
+		assert.True(t, IsRateLimited(err))
+		assert.ErrorIs(t, err, ErrDiscoRateLimited)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		dst := t.TempDir()
+		_, err := c.DownloadFile(context.Background(), srv.URL+"/jdk.zip", dst)
+		var apiErr *DiscoAPIError
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+		assert.NoFileExists(t, dst+"/jdk.zip")
+	})
+}
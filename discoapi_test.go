@@ -1,13 +1,121 @@
 package jlib
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// testPackage is a package served by newTestDiscoServer
+type testPackage struct {
+	GetPackagesResponse
+	Archive      []byte
+	Checksum     string // sha256 published for the archive, computed from Archive if empty
+	ChecksumType string // Type published with Checksum, sha256 if empty
+	Signature    []byte // Detached signature of the archive, no signature_uri is published if nil
+}
+
+// newTestDiscoServer serves the Disco endpoints needed to find, resolve and download the given packages.
+// Packages are filtered by the distribution, jdk_version and archive_type query parameters.
+func newTestDiscoServer(t *testing.T, pkgs ...testPackage) *httptest.Server {
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+
+	find := func(id string) *testPackage {
+		for i := range pkgs {
+			if pkgs[i].ID == id {
+				return &pkgs[i]
+			}
+		}
+		return nil
+	}
+	matches := func(value string, filter string) bool {
+		return filter == "" || slices.Contains(strings.Split(filter, ","), value)
+	}
+
+	mux.HandleFunc("/packages", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		result := []GetPackagesResponse{}
+		for _, p := range pkgs {
+			if matches(p.Distribution, q.Get("distribution")) &&
+				matches(p.ArchiveType, q.Get("archive_type")) &&
+				matches(strconv.Itoa(p.JDKVersion), q.Get("jdk_version")) {
+				result = append(result, p.GetPackagesResponse)
+			}
+		}
+		json.NewEncoder(w).Encode(DiscoResponseWrapper[[]GetPackagesResponse]{Result: result})
+	})
+	mux.HandleFunc("/ids/{id}", func(w http.ResponseWriter, r *http.Request) {
+		p := find(r.PathValue("id"))
+		if p == nil {
+			http.NotFound(w, r)
+			return
+		}
+		checksum := p.Checksum
+		if checksum == "" {
+			sum := sha256.Sum256(p.Archive)
+			checksum = hex.EncodeToString(sum[:])
+		}
+		checksumType := p.ChecksumType
+		if checksumType == "" {
+			checksumType = "sha256"
+		}
+		info := PackageInfo{
+			Filename:          p.Filename,
+			DirectDownloadURI: srv.URL + "/files/" + p.Filename,
+			Checksum:          checksum,
+			ChecksumType:      checksumType,
+		}
+		if p.Signature != nil {
+			info.SignatureURI = srv.URL + "/files/" + p.Filename + ".sig"
+		}
+		json.NewEncoder(w).Encode(DiscoResponseWrapper[[]PackageInfo]{Result: []PackageInfo{info}})
+	})
+	mux.HandleFunc("/ids/{id}/redirect", func(w http.ResponseWriter, r *http.Request) {
+		p := find(r.PathValue("id"))
+		if p == nil {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, "/files/"+p.Filename, http.StatusFound)
+	})
+	mux.HandleFunc("/files/{filename}", func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range pkgs {
+			if p.Filename == r.PathValue("filename") {
+				w.Write(p.Archive)
+				return
+			}
+			if p.Signature != nil && p.Filename+".sig" == r.PathValue("filename") {
+				w.Write(p.Signature)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestDiscoClient returns a client for srv that neither retries nor caches
+func newTestDiscoClient(srv *httptest.Server) *DiscoClient {
+	c := NewDiscoClient(srv.URL)
+	c.Retry = nil
+	return c
+}
+
 func TestStructToMap(t *testing.T) {
 	type testStruct struct {
 		A string
@@ -20,6 +128,72 @@ func TestStructToMap(t *testing.T) {
 	assert.Equal(t, 1, result["B"])
 }
 
+func TestDiscoClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/disco/v3.0/distributions/zulu", r.URL.Path)
+		assert.Equal(t, "jlib-test", r.Header.Get("User-Agent"))
+		assert.Equal(t, "true", r.URL.Query().Get("include_versions"))
+		assert.Equal(t, "public", r.URL.Query().Get("discovery_scope_id"))
+		w.Write([]byte(`{"result":[{"name":"ZuluCE","api_parameter":"zulu","versions":["21.0.1"]}],"message":""}`))
+	}))
+	defer srv.Close()
+
+	c := NewDiscoClient(srv.URL + "/disco/v3.0")
+	c.UserAgent = "jlib-test"
+	c.DefaultQuery = map[string]interface{}{"discovery_scope_id": "public", "include_versions": false}
+
+	result, err := c.GetDistribution(context.Background(), "zulu", &GetDistributionOptions{IncludeVersions: true})
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "zulu", result[0].ApiParameter)
+	assert.Equal(t, []string{"21.0.1"}, result[0].Versions)
+}
+
+func TestGetPackageInfoOffline(t *testing.T) {
+	srv := newTestDiscoServer(t, testPackage{
+		GetPackagesResponse: GetPackagesResponse{ID: "abc", Filename: "jdk-17.zip"},
+		Archive:             []byte("archive"),
+	})
+	c := newTestDiscoClient(srv)
+
+	info, err := c.GetPackageInfo(context.Background(), "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "jdk-17.zip", info.Filename)
+	assert.Equal(t, srv.URL+"/files/jdk-17.zip", info.DirectDownloadURI)
+	assert.Equal(t, "sha256", info.ChecksumType)
+
+	_, err = c.GetPackageInfo(context.Background(), "missing")
+	assert.True(t, IsNotFound(err))
+
+	// The direct download URI is used, the redirect endpoint is not needed
+	var redirects int
+	c.HTTPClient = &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		redirects++
+		return nil
+	}}
+	dst := t.TempDir()
+	file, err := c.DownloadJavaByID(context.Background(), "abc", dst)
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(dst, "jdk-17.zip"), file.Name())
+	assert.Zero(t, redirects)
+}
+
+func TestDownloadFileCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 1024))
+		w.(http.Flusher).Flush()
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	dst := t.TempDir()
+	_, err := NewDiscoClient(srv.URL).DownloadFile(ctx, srv.URL+"/jdk.zip", dst)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoFileExists(t, path.Join(dst, "jdk.zip"))
+}
+
 func TestDownloadFile(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping test in short mode.")
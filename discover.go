@@ -0,0 +1,134 @@
+package jlib
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+var ErrExternalPackage = fmt.Errorf("package is not managed by jlib")
+
+// DefaultDiscoverRoots returns the directories where JDKs are commonly installed on this machine:
+// the system locations of the platform, SDKMAN, jabba and JAVA_HOME
+func DefaultDiscoverRoots() []string {
+	var roots []string
+	switch runtime.GOOS {
+	case "linux":
+		roots = append(roots, "/usr/lib/jvm", "/usr/java", "/opt/java")
+	case "darwin":
+		roots = append(roots, "/Library/Java/JavaVirtualMachines")
+	case "windows":
+		if programFiles := os.Getenv("ProgramFiles"); programFiles != "" {
+			for _, vendor := range []string{"Java", "Eclipse Adoptium", "Zulu", "Amazon Corretto", "Microsoft", "BellSoft"} {
+				roots = append(roots, path.Join(filepath.ToSlash(programFiles), vendor))
+			}
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		roots = append(roots, path.Join(home, ".sdkman", "candidates", "java"), path.Join(home, ".jabba", "jdk"))
+		if runtime.GOOS == "darwin" {
+			roots = append(roots, path.Join(home, "Library", "Java", "JavaVirtualMachines"))
+		}
+	}
+	if javaHome := os.Getenv("JAVA_HOME"); javaHome != "" {
+		roots = append(roots, javaHome)
+	}
+	return roots
+}
+
+// Discover finds the JDKs installed outside of jlib, DefaultDiscoverRoots are scanned when no roots are given.
+// A root is either a JDK itself or a directory containing JDKs. Only directories with a java executable
+// and a release file are reported, the metadata comes from the release file and the directory name becomes the ID.
+// A JDK reachable through several symlinks is reported once.
+func Discover(roots ...string) []*JavaPackage {
+	if len(roots) == 0 {
+		roots = DefaultDiscoverRoots()
+	}
+
+	var javas []*JavaPackage
+	seen := map[string]bool{}
+	add := func(dir string) {
+		real, err := filepath.EvalSymlinks(dir)
+		if err != nil || seen[real] {
+			return
+		}
+		java, err := loadExternalPackage(dir)
+		if err != nil {
+			return
+		}
+		seen[real] = true
+		javas = append(javas, java)
+	}
+
+	for _, root := range roots {
+		if isJavaDir(root) {
+			add(root)
+			continue
+		}
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			continue
+		}
+		// Real directories first so that aliases like "current" or "default-java" do not hide the actual name
+		var links []string
+		for _, entry := range entries {
+			dir := path.Join(root, entry.Name())
+			if entry.Type()&os.ModeSymlink != 0 {
+				links = append(links, dir)
+				continue
+			}
+			if entry.IsDir() {
+				add(dir)
+			}
+		}
+		for _, dir := range links {
+			add(dir)
+		}
+	}
+	return javas
+}
+
+// isJavaDir reports whether dir looks like a JDK identified by its release file
+func isJavaDir(dir string) bool {
+	if validatePackage(dir) != nil {
+		return false
+	}
+	_, err := os.Stat(path.Join(javaHome(dir), "release"))
+	return err == nil
+}
+
+// loadExternalPackage describes a JDK found outside of DataDir from its release file
+func loadExternalPackage(dir string) (*JavaPackage, error) {
+	if !isJavaDir(dir) {
+		return nil, fmt.Errorf("%s is not a java installation", dir)
+	}
+	meta, err := metaFromRelease(path.Base(dir), javaHome(dir))
+	if err != nil {
+		return nil, err
+	}
+	java := newJavaPackage(meta, dir)
+	java.External = true
+	return java, nil
+}
+
+// discoverExternal returns the discovered JDKs that are not located inside DataDir
+func (vm *VersionManager) discoverExternal() []*JavaPackage {
+	dataDir, err := filepath.EvalSymlinks(vm.DataDir)
+	if err != nil {
+		dataDir = vm.DataDir
+	}
+
+	var javas []*JavaPackage
+	for _, java := range Discover(vm.DiscoverRoots...) {
+		real, err := filepath.EvalSymlinks(java.JavaDir)
+		if err == nil && strings.HasPrefix(real, dataDir+string(os.PathSeparator)) {
+			continue
+		}
+		javas = append(javas, java)
+	}
+	return javas
+}
@@ -0,0 +1,63 @@
+package jlib
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need privileges on windows")
+	}
+	jvm := t.TempDir()
+	makeTestJDK(t, path.Join(jvm, "java-17-temurin"), testTemurinRelease)
+	assert.NoError(t, os.Symlink("java-17-temurin", path.Join(jvm, "default-java")))
+	assert.NoError(t, os.MkdirAll(path.Join(jvm, "not-a-jdk", "bin"), 0755))
+	noRelease := makeTestJDK(t, path.Join(jvm, "no-release"), testTemurinRelease)
+	assert.NoError(t, os.Remove(path.Join(noRelease, "release")))
+
+	javaHome := makeTestJDK(t, path.Join(t.TempDir(), "custom"), "IMPLEMENTOR=\"Azul Systems, Inc.\"\nJAVA_VERSION=\"11.0.21\"\n")
+
+	javas := Discover(jvm, javaHome, path.Join(jvm, "missing"))
+	assert.Len(t, javas, 2)
+	assert.Equal(t, "java-17-temurin", javas[0].ID)
+	assert.Equal(t, "temurin", javas[0].Distribution)
+	assert.True(t, javas[0].External)
+	assert.Equal(t, "custom", javas[1].ID)
+	assert.Equal(t, "zulu", javas[1].Distribution)
+	assert.Equal(t, 11, javas[1].JDKVersion)
+}
+
+func TestVersionManagerIncludeExternal(t *testing.T) {
+	root := t.TempDir()
+	makeTestJDK(t, path.Join(root, "17.0.9-tem"), testTemurinRelease)
+
+	dataDir := path.Join(root, "jlib")
+	installed := makeTestJDK(t, path.Join(dataDir, "zulu17"), testTemurinRelease)
+	assert.NoError(t, saveStructToJSONFile(&PackageMetaInfo{ID: "abc"}, path.Join(installed, "meta.json")))
+	vm := NewVersionManager(dataDir)
+	// Packages of DataDir must not be reported twice
+	vm.DiscoverRoots = []string{root, dataDir}
+
+	javas, err := vm.List()
+	assert.NoError(t, err)
+	assert.Len(t, javas, 1)
+
+	vm.IncludeExternal = true
+	javas, err = vm.List()
+	assert.NoError(t, err)
+	assert.Len(t, javas, 2)
+	assert.False(t, javas[0].External)
+
+	java, err := vm.Use("temurin", 17)
+	assert.NoError(t, err)
+	assert.Equal(t, "17.0.9-tem", java.ID)
+	assert.True(t, java.External)
+
+	assert.ErrorIs(t, vm.Remove(java), ErrExternalPackage)
+	assert.DirExists(t, java.JavaDir)
+}
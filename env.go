@@ -0,0 +1,166 @@
+package jlib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Variable recording the Java home activated by jlib, so that the next activation can remove it from PATH
+const JlibJavaHomeEnv = "JLIB_JAVA_HOME"
+
+// Shells supported by RenderEnv
+const (
+	ShellBash       = "bash"
+	ShellZsh        = "zsh"
+	ShellFish       = "fish"
+	ShellPowerShell = "powershell"
+)
+
+// EnvVar is a change of an environment variable
+type EnvVar struct {
+	Name  string
+	Value string
+	Unset bool // Remove the variable instead of setting it
+}
+
+// Env returns the changes activating java in the environment of the current process, see EnvFrom
+func Env(java *JavaPackage) []EnvVar {
+	return EnvFrom(java, os.Environ())
+}
+
+// EnvFrom returns the changes activating java in environ, given in the form of os.Environ.
+// JAVA_HOME is set and the bin directory of java is put first in PATH, after removing the one of
+// the Java previously activated by jlib. A nil java deactivates the previous Java.
+func EnvFrom(java *JavaPackage, environ []string) []EnvVar {
+	previous := lookupEnv(environ, JlibJavaHomeEnv)
+	javaHome := lookupEnv(environ, "JAVA_HOME")
+
+	var entries []string
+	if current := lookupEnv(environ, "PATH"); current != "" {
+		entries = filepath.SplitList(current)
+	}
+	if previous != "" {
+		previousBin := filepath.Join(previous, "bin")
+		entries = removeEntry(entries, previousBin)
+	}
+
+	if java == nil {
+		vars := []EnvVar{{Name: "PATH", Value: strings.Join(entries, string(os.PathListSeparator))}}
+		if previous != "" && javaHome == previous {
+			vars = append(vars, EnvVar{Name: "JAVA_HOME", Unset: true})
+		}
+		return append(vars, EnvVar{Name: JlibJavaHomeEnv, Unset: true})
+	}
+
+	home := filepath.FromSlash(java.JavaHome)
+	bin := filepath.Join(home, "bin")
+	entries = append([]string{bin}, removeEntry(entries, bin)...)
+	return []EnvVar{
+		{Name: "JAVA_HOME", Value: home},
+		{Name: "PATH", Value: strings.Join(entries, string(os.PathListSeparator))},
+		{Name: JlibJavaHomeEnv, Value: home},
+	}
+}
+
+// lookupEnv returns the value of name in environ, names are case insensitive on windows
+func lookupEnv(environ []string, name string) string {
+	value := ""
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		if k == name || (runtime.GOOS == "windows" && strings.EqualFold(k, name)) {
+			value = v
+		}
+	}
+	return value
+}
+
+func removeEntry(entries []string, entry string) []string {
+	var result []string
+	for _, e := range entries {
+		if filepath.Clean(e) != filepath.Clean(entry) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// ApplyEnv applies the changes to environ, given in the form of os.Environ, and returns the new environment
+func ApplyEnv(environ []string, vars []EnvVar) []string {
+	result := make([]string, 0, len(environ)+len(vars))
+	for _, kv := range environ {
+		k, _, _ := strings.Cut(kv, "=")
+		changed := false
+		for _, v := range vars {
+			if k == v.Name || (runtime.GOOS == "windows" && strings.EqualFold(k, v.Name)) {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			result = append(result, kv)
+		}
+	}
+	for _, v := range vars {
+		if !v.Unset {
+			result = append(result, v.Name+"="+v.Value)
+		}
+	}
+	return result
+}
+
+// RenderEnv returns a snippet applying the changes when evaluated by shell:
+// eval "$(...)" in bash and zsh, ... | source in fish and ... | Invoke-Expression in PowerShell
+func RenderEnv(shell string, vars []EnvVar) (string, error) {
+	var b strings.Builder
+	for _, v := range vars {
+		switch shell {
+		case ShellBash, ShellZsh, "sh":
+			if v.Unset {
+				fmt.Fprintf(&b, "unset %s\n", v.Name)
+			} else {
+				fmt.Fprintf(&b, "export %s=%s\n", v.Name, quoteSh(v.Value))
+			}
+		case ShellFish:
+			if v.Unset {
+				fmt.Fprintf(&b, "set -e %s\n", v.Name)
+				continue
+			}
+			values := []string{v.Value}
+			if v.Name == "PATH" {
+				// PATH is a list in fish
+				values = filepath.SplitList(v.Value)
+			}
+			for i := range values {
+				values[i] = quoteFish(values[i])
+			}
+			fmt.Fprintf(&b, "set -gx %s %s\n", v.Name, strings.Join(values, " "))
+		case ShellPowerShell, "pwsh":
+			if v.Unset {
+				fmt.Fprintf(&b, "Remove-Item Env:%s -ErrorAction SilentlyContinue\n", v.Name)
+			} else {
+				fmt.Fprintf(&b, "$env:%s = %s\n", v.Name, quotePowerShell(v.Value))
+			}
+		default:
+			return "", fmt.Errorf("unsupported shell %q", shell)
+		}
+	}
+	return b.String(), nil
+}
+
+func quoteSh(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteFish(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func quotePowerShell(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,66 @@
+package jlib
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvFrom(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("paths are unix paths")
+	}
+	java17 := &JavaPackage{JavaHome: "/data/temurin-17"}
+	java21 := &JavaPackage{JavaHome: "/data/zulu-21"}
+
+	environ := []string{"HOME=/home/user", "PATH=/usr/local/bin:/usr/bin", "JAVA_HOME=/usr/lib/jvm/default"}
+	vars := EnvFrom(java17, environ)
+	assert.Equal(t, []EnvVar{
+		{Name: "JAVA_HOME", Value: "/data/temurin-17"},
+		{Name: "PATH", Value: "/data/temurin-17/bin:/usr/local/bin:/usr/bin"},
+		{Name: JlibJavaHomeEnv, Value: "/data/temurin-17"},
+	}, vars)
+
+	// Switching removes the previous Java from PATH
+	environ = ApplyEnv(environ, vars)
+	assert.Equal(t, []string{"HOME=/home/user", "JAVA_HOME=/data/temurin-17", "PATH=/data/temurin-17/bin:/usr/local/bin:/usr/bin", "JLIB_JAVA_HOME=/data/temurin-17"}, environ)
+	vars = EnvFrom(java21, environ)
+	assert.Equal(t, "/data/zulu-21/bin:/usr/local/bin:/usr/bin", vars[1].Value)
+
+	// Deactivating restores PATH and unsets JAVA_HOME
+	environ = ApplyEnv(environ, vars)
+	vars = EnvFrom(nil, environ)
+	assert.Equal(t, []EnvVar{
+		{Name: "PATH", Value: "/usr/local/bin:/usr/bin"},
+		{Name: "JAVA_HOME", Unset: true},
+		{Name: JlibJavaHomeEnv, Unset: true},
+	}, vars)
+	assert.Equal(t, []string{"HOME=/home/user", "PATH=/usr/local/bin:/usr/bin"}, ApplyEnv(environ, vars))
+}
+
+func TestRenderEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("paths are unix paths")
+	}
+	vars := []EnvVar{
+		{Name: "JAVA_HOME", Value: "/opt/it's java"},
+		{Name: "PATH", Value: "/opt/java/bin:/usr/bin"},
+		{Name: JlibJavaHomeEnv, Unset: true},
+	}
+
+	out, err := RenderEnv(ShellBash, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "export JAVA_HOME='/opt/it'\\''s java'\nexport PATH='/opt/java/bin:/usr/bin'\nunset JLIB_JAVA_HOME\n", out)
+
+	out, err = RenderEnv(ShellFish, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "set -gx JAVA_HOME '/opt/it\\'s java'\nset -gx PATH '/opt/java/bin' '/usr/bin'\nset -e JLIB_JAVA_HOME\n", out)
+
+	out, err = RenderEnv(ShellPowerShell, vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "$env:JAVA_HOME = '/opt/it''s java'\n$env:PATH = '/opt/java/bin:/usr/bin'\nRemove-Item Env:JLIB_JAVA_HOME -ErrorAction SilentlyContinue\n", out)
+
+	_, err = RenderEnv("tcsh", vars)
+	assert.Error(t, err)
+}
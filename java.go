@@ -1,10 +1,58 @@
 package jlib
 
+import (
+	"os"
+	"path"
+)
+
 type PackageMetaInfo = GetPackagesResponse
 
+// packageMeta is the content of meta.json: the Disco package and the result of the signature check
+type packageMeta struct {
+	PackageMetaInfo
+	Signature *SignatureVerification `json:"signature,omitempty"` // Result of the signature check, nil if none was made
+}
+
+// javaPackage returns the package installed in dir described by the meta.json content
+func (meta *packageMeta) javaPackage(dir string) *JavaPackage {
+	java := newJavaPackage(&meta.PackageMetaInfo, dir)
+	java.Signature = meta.Signature
+	return java
+}
+
 // JavaPackage represents an installed Java package
 type JavaPackage struct {
 	*PackageMetaInfo
-	JavaDir      string // Path to the java installation directory
-	JavaExecPath string // Path to the java executable
+	JavaDir      string                 // Path to the java installation directory
+	JavaHome     string                 // Path to use as JAVA_HOME, differs from JavaDir for macOS bundles (Contents/Home)
+	JavaExecPath string                 // Path to the java executable
+	Release      *ReleaseInfo           // Content of the release file, nil if the package has none
+	Signature    *SignatureVerification // Result of the signature check made on install, nil if none was made
+	External     bool                   // Installed outside of DataDir, see Discover
+	Linked       bool                   // Registered with Link, ID is the link name
+}
+
+// javaHome returns the directory containing bin/java, which is Contents/Home in macOS bundles
+func javaHome(dir string) string {
+	if info, err := os.Stat(path.Join(dir, "Contents", "Home")); err == nil && info.IsDir() {
+		return path.Join(dir, "Contents", "Home")
+	}
+	return dir
+}
+
+func newJavaPackage(meta *PackageMetaInfo, dir string) *JavaPackage {
+	home := javaHome(dir)
+	release, _ := ReadReleaseInfo(home)
+	return &JavaPackage{
+		PackageMetaInfo: meta,
+		JavaDir:         dir,
+		JavaHome:        home,
+		JavaExecPath:    path.Join(home, "bin", addExeIfWindows("java")),
+		Release:         release,
+	}
+}
+
+// Version returns the parsed java version of the package
+func (java *JavaPackage) Version() (JavaVersion, error) {
+	return ParseJavaVersion(packageVersion(java.PackageMetaInfo))
 }
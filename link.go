@@ -0,0 +1,119 @@
+package jlib
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Name of the directory inside DataDir where linked packages are registered
+const linksDirName = ".links"
+
+var ErrLinkExists = fmt.Errorf("link already exists")
+
+// linkInfo is the content of DataDir/.links/<name>.json
+type linkInfo struct {
+	Dir  string           `json:"dir"`  // Absolute path of the linked directory
+	Meta *PackageMetaInfo `json:"meta"` // Metadata synthesized from the release file when linked
+}
+
+func (vm *VersionManager) linkPath(name string) string {
+	return path.Join(vm.DataDir, linksDirName, name+".json")
+}
+
+func validateLinkName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid link name %q", name)
+	}
+	return nil
+}
+
+// Link registers a JDK living outside of DataDir, e.g. a local build, under name.
+// The directory must contain bin/java and a release file, the metadata is synthesized from the latter
+// and name becomes the package ID. The directory is never modified, see Unlink.
+func (vm *VersionManager) Link(name string, dir string) (*JavaPackage, error) {
+	if err := validateLinkName(name); err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	dir = filepath.ToSlash(dir)
+	if !isJavaDir(dir) {
+		return nil, fmt.Errorf("%s is not a java installation", dir)
+	}
+
+	if _, err := os.Stat(vm.linkPath(name)); err == nil {
+		return nil, ErrLinkExists
+	}
+	if existing, err := vm.GetJavaByID(name); err == nil && !existing.Linked {
+		return nil, fmt.Errorf("package %s already exists", name)
+	}
+
+	meta, err := metaFromRelease(name, javaHome(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release file: %w", err)
+	}
+
+	if err := os.MkdirAll(path.Join(vm.DataDir, linksDirName), 0755); err != nil {
+		return nil, err
+	}
+	if err := saveStructToJSONFile(&linkInfo{Dir: dir, Meta: meta}, vm.linkPath(name)); err != nil {
+		return nil, fmt.Errorf("failed to save link: %w", err)
+	}
+	vm.refreshShims()
+	return newLinkedPackage(meta, dir), nil
+}
+
+// Unlink forgets a package registered with Link, the linked directory is left untouched
+func (vm *VersionManager) Unlink(name string) error {
+	if err := validateLinkName(name); err != nil {
+		return err
+	}
+	err := os.Remove(vm.linkPath(name))
+	if os.IsNotExist(err) {
+		return ErrJavaNotFound
+	}
+	if err != nil {
+		return err
+	}
+	vm.refreshShims()
+	return nil
+}
+
+func newLinkedPackage(meta *PackageMetaInfo, dir string) *JavaPackage {
+	java := newJavaPackage(meta, dir)
+	java.External = true
+	java.Linked = true
+	return java
+}
+
+// scanLinks loads the linked packages, links whose directory disappeared are reported as problems
+func (vm *VersionManager) scanLinks(result *ListResult) {
+	entries, err := os.ReadDir(path.Join(vm.DataDir, linksDirName))
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		file := path.Join(vm.DataDir, linksDirName, entry.Name())
+		link, err := readStructFromJSONFile[linkInfo](file)
+		if err == nil && link.Meta == nil {
+			err = fmt.Errorf("no package metadata")
+		}
+		if err != nil {
+			result.Problems = append(result.Problems, ListProblem{Dir: file, Reason: "invalid link", Err: err})
+			continue
+		}
+		if err := validatePackage(link.Dir); err != nil {
+			result.Problems = append(result.Problems, ListProblem{Dir: link.Dir, Reason: "missing link target", Err: err})
+			continue
+		}
+		result.Packages = append(result.Packages, newLinkedPackage(link.Meta, link.Dir))
+	}
+}
@@ -0,0 +1,54 @@
+package jlib
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionManagerLink(t *testing.T) {
+	vm := NewVersionManager(t.TempDir())
+	build := makeTestJDK(t, path.Join(t.TempDir(), "build", "jdk"), testTemurinRelease)
+
+	_, err := vm.Link("local", t.TempDir())
+	assert.Error(t, err)
+	_, err = vm.Link("../escape", build)
+	assert.Error(t, err)
+
+	j, err := vm.Link("local", build)
+	assert.NoError(t, err)
+	assert.Equal(t, "local", j.ID)
+	assert.Equal(t, "temurin", j.Distribution)
+	assert.True(t, j.Linked)
+	assert.True(t, j.External)
+
+	_, err = vm.Link("local", build)
+	assert.ErrorIs(t, err, ErrLinkExists)
+
+	j, err = vm.GetJavaByID("local")
+	assert.NoError(t, err)
+	assert.Equal(t, build, j.JavaDir)
+
+	j, err = vm.Use("temurin", 17)
+	assert.NoError(t, err)
+	assert.Equal(t, "local", j.ID)
+
+	assert.NoError(t, vm.Remove(j))
+	assert.FileExists(t, path.Join(build, "bin", addExeIfWindows("java")))
+	_, err = vm.GetJavaByID("local")
+	assert.Error(t, err)
+	assert.ErrorIs(t, vm.Unlink("local"), ErrJavaNotFound)
+
+	// A link whose directory disappeared is reported as a problem
+	_, err = vm.Link("gone", build)
+	assert.NoError(t, err)
+	assert.NoError(t, os.RemoveAll(build))
+	result, err := vm.Scan()
+	assert.NoError(t, err)
+	assert.Empty(t, result.Packages)
+	assert.Len(t, result.Problems, 1)
+	assert.Equal(t, "missing link target", result.Problems[0].Reason)
+	assert.NoError(t, vm.Unlink("gone"))
+}
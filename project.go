@@ -0,0 +1,240 @@
+package jlib
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Project version files, looked up in this order in each directory
+const (
+	JavaVersionFile  = ".java-version"  // jenv and most version managers: a single version, e.g. 17.0.9 or temurin-17
+	SdkmanrcFile     = ".sdkmanrc"      // SDKMAN: java=17.0.9-tem
+	ToolVersionsFile = ".tool-versions" // asdf: java temurin-17.0.9+9
+)
+
+var projectFileNames = []string{JavaVersionFile, SdkmanrcFile, ToolVersionsFile}
+
+var ErrProjectFileNotFound = fmt.Errorf("no java version file found")
+
+// ProjectFile is the java version requested by a project version file
+type ProjectFile struct {
+	Path  string    // Path of the version file
+	Value string    // Version as written in the file
+	Spec  *JavaSpec // Parsed version, nil when the file asks for the system java ("system" in asdf)
+}
+
+// sdkmanVendors maps the vendor suffix of SDKMAN identifiers (17.0.9-tem) to the Disco distribution name
+var sdkmanVendors = map[string]string{
+	"tem":        "temurin",
+	"amzn":       "corretto",
+	"librca":     "liberica",
+	"nik":        "liberica_native",
+	"zulu":       "zulu",
+	"ms":         "microsoft",
+	"sapmchn":    "sap_machine",
+	"sem":        "semeru",
+	"open":       "oracle_open_jdk",
+	"oracle":     "oracle",
+	"graal":      "graalvm",
+	"graalce":    "graalvm_community",
+	"gln":        "gluon_graalvm",
+	"mandrel":    "mandrel",
+	"dragonwell": "dragonwell",
+	"albba":      "dragonwell",
+	"kona":       "kona",
+	"jbr":        "jetbrains",
+	"bisheng":    "bisheng",
+	"trava":      "trava",
+	"adpt":       "aoj",
+}
+
+// vendorNames maps the vendor prefix of asdf (temurin-17.0.9+9) and jenv (temurin64-17.0.9) names to the Disco distribution name.
+// Names absent from the map are used as they are.
+var vendorNames = map[string]string{
+	"adoptopenjdk":      "aoj",
+	"openjdk":           "",
+	"oracle-openjdk":    "oracle_open_jdk",
+	"sapmachine":        "sap_machine",
+	"semeru-openj9":     "semeru",
+	"graalvm-community": "graalvm_community",
+	"oracle-graalvm":    "graalvm",
+	"amazon-corretto":   "corretto",
+}
+
+// FindProjectFile looks for a project version file in dir and its parents, the closest one wins.
+// Files that do not mention java, e.g. a .tool-versions listing only nodejs, are skipped.
+func FindProjectFile(dir string) (*ProjectFile, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		for _, name := range projectFileNames {
+			file := filepath.Join(dir, name)
+			if _, err := os.Stat(file); err != nil {
+				continue
+			}
+			project, err := ParseProjectFile(file)
+			if err == ErrProjectFileNotFound {
+				continue
+			}
+			return project, err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrProjectFileNotFound
+		}
+		dir = parent
+	}
+}
+
+// ParseProjectFile reads a .java-version, .sdkmanrc or .tool-versions file, the format is given by the file name.
+// ErrProjectFileNotFound is returned when the file has no java entry.
+func ParseProjectFile(file string) (*ProjectFile, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	name := path.Base(filepath.ToSlash(file))
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		var value string
+		var spec *JavaSpec
+		switch name {
+		case SdkmanrcFile:
+			key, v, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) != "java" {
+				continue
+			}
+			value = strings.TrimSpace(v)
+			spec, err = parseSdkmanVersion(value)
+		case ToolVersionsFile:
+			fields := strings.Fields(line)
+			if len(fields) < 2 || fields[0] != "java" {
+				continue
+			}
+			// Further fields are fallbacks, only the first version is used
+			value = fields[1]
+			if value == "system" {
+				return &ProjectFile{Path: file, Value: value}, nil
+			}
+			spec, err = parseVendorVersion(value)
+		default:
+			value = line
+			if value == "system" {
+				return &ProjectFile{Path: file, Value: value}, nil
+			}
+			spec, err = parseVendorVersion(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		return &ProjectFile{Path: file, Value: value, Spec: spec}, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, ErrProjectFileNotFound
+}
+
+// parseSdkmanVersion parses an SDKMAN identifier such as 17.0.9-tem, 21.0.1.fx-librca or 22.ea.27-open
+func parseSdkmanVersion(value string) (*JavaSpec, error) {
+	version, vendor, ok := strings.Cut(value, "-")
+	if !ok {
+		return ParseJavaSpec(value)
+	}
+	version = strings.Replace(version, ".ea.", "-ea+", 1)
+	distribution, ok := sdkmanVendors[vendor]
+	if !ok {
+		return nil, fmt.Errorf("unknown SDKMAN vendor %q", vendor)
+	}
+	return newVendorSpec(distribution, version)
+}
+
+// parseVendorVersion parses a version optionally prefixed by a vendor: 17, 17.0.9+9, temurin-17.0.9+9,
+// liberica-javafx-21.0.1 or the jlib syntax accepted by ParseJavaSpec
+func parseVendorVersion(value string) (*JavaSpec, error) {
+	// The vendor ends at the first dash followed by a digit
+	for i := 0; i < len(value)-1; i++ {
+		if value[i] != '-' || value[i+1] < '0' || value[i+1] > '9' {
+			continue
+		}
+		if i == 0 {
+			break
+		}
+		vendor := strings.ToLower(value[:i])
+		javaFX := false
+		if strings.HasSuffix(vendor, "-javafx") {
+			vendor, javaFX = strings.TrimSuffix(vendor, "-javafx"), true
+		}
+		// jenv names carry the bitness, e.g. openjdk64-17.0.9
+		vendor = strings.TrimSuffix(vendor, "64")
+
+		distribution, ok := vendorNames[vendor]
+		if !ok {
+			distribution = strings.ReplaceAll(vendor, "-", "_")
+		}
+		spec, err := newVendorSpec(distribution, value[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		spec.JavaFX = spec.JavaFX || javaFX
+		useDistributionVersion(spec)
+		return spec, nil
+	}
+	return ParseJavaSpec(value)
+}
+
+// newVendorSpec returns the spec of a version published by a distribution, a .fx suffix asks for JavaFX
+func newVendorSpec(distribution string, version string) (*JavaSpec, error) {
+	javaFX := false
+	for _, suffix := range []string{".fx", ".crac"} {
+		if strings.HasSuffix(version, suffix) {
+			version = strings.TrimSuffix(version, suffix)
+			javaFX = javaFX || suffix == ".fx"
+		}
+	}
+	spec, err := ParseJavaSpec(version)
+	if err != nil {
+		return nil, err
+	}
+	spec.Distribution = distribution
+	spec.JavaFX = javaFX
+	return spec, nil
+}
+
+// useDistributionVersion rewrites the spec of the asdf names carrying the version of the distribution
+// rather than the Java version: corretto-17.0.9.8.1 is 17.0.9, corretto-8.392.08.1 is 8.0.392 and
+// zulu-17.46.19 is the Zulu 17 whose distribution version is 17.46.19.
+// Java versions have an interim number of 0, the distribution versions of Zulu and Corretto 8 do not.
+func useDistributionVersion(spec *JavaSpec) {
+	numbers := strings.Split(spec.Version, ".")
+	if len(numbers) < 3 {
+		return
+	}
+	switch {
+	case spec.Distribution == "corretto" && numbers[1] == "0":
+		// The Java version followed by the build and the Corretto revision
+		spec.Version = strings.Join(numbers[:3], ".")
+	case spec.Distribution == "corretto":
+		// Corretto 8 is numbered 8.update.build.revision
+		spec.Version = numbers[0] + ".0." + numbers[1]
+	case spec.Distribution == "zulu" && numbers[1] != "0":
+		spec.DistributionVersion = spec.Version
+		spec.Version = numbers[0]
+	}
+}
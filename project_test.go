@@ -0,0 +1,88 @@
+package jlib
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeProjectFile(t *testing.T, dir string, name string, content string) string {
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	file := path.Join(dir, name)
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestParseProjectFile(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		content  string
+		expected JavaSpec
+	}{
+		{JavaVersionFile, "17\n", JavaSpec{Version: "17"}},
+		{JavaVersionFile, "1.8\n", JavaSpec{Version: "1.8"}},
+		{JavaVersionFile, "temurin64-17.0.9\n", JavaSpec{Distribution: "temurin", Version: "17.0.9"}},
+		{JavaVersionFile, "openjdk64-21.0.1\n", JavaSpec{Version: "21.0.1"}},
+		{JavaVersionFile, "zulu@~11\n", JavaSpec{Distribution: "zulu", Version: "~11"}},
+		{SdkmanrcFile, "# Enable auto-env through the sdkman_auto_env config\njava=17.0.9-tem\nmaven=3.9.5\n", JavaSpec{Distribution: "temurin", Version: "17.0.9"}},
+		{SdkmanrcFile, "java=21.0.1.fx-librca\n", JavaSpec{Distribution: "liberica", Version: "21.0.1", JavaFX: true}},
+		{SdkmanrcFile, "java=8.0.392-amzn\n", JavaSpec{Distribution: "corretto", Version: "8.0.392"}},
+		{SdkmanrcFile, "java=22.ea.27-open\n", JavaSpec{Distribution: "oracle_open_jdk", Version: "22-ea+27", ReleaseStatus: "ea"}},
+		{ToolVersionsFile, "nodejs 20.9.0\njava temurin-17.0.9+9 system\n", JavaSpec{Distribution: "temurin", Version: "17.0.9+9"}},
+		{ToolVersionsFile, "java liberica-javafx-21.0.1+12\n", JavaSpec{Distribution: "liberica", Version: "21.0.1+12", JavaFX: true}},
+		{ToolVersionsFile, "java semeru-openj9-17.0.9+9_openj9-0.41.0\n", JavaSpec{Distribution: "semeru", Version: "17.0.9+9_openj9-0.41.0"}},
+		{ToolVersionsFile, "java graalvm-community-21.0.1\n", JavaSpec{Distribution: "graalvm_community", Version: "21.0.1"}},
+		{ToolVersionsFile, "java corretto-17.0.9.8.1\n", JavaSpec{Distribution: "corretto", Version: "17.0.9"}},
+		{ToolVersionsFile, "java corretto-8.392.08.1\n", JavaSpec{Distribution: "corretto", Version: "8.0.392"}},
+		{ToolVersionsFile, "java zulu-17.46.19\n", JavaSpec{Distribution: "zulu", Version: "17", DistributionVersion: "17.46.19"}},
+		{ToolVersionsFile, "java zulu-17.0.9\n", JavaSpec{Distribution: "zulu", Version: "17.0.9"}},
+	} {
+		file := writeProjectFile(t, t.TempDir(), c.name, c.content)
+		project, err := ParseProjectFile(file)
+		if assert.NoError(t, err, c.content) {
+			assert.Equal(t, c.expected, *project.Spec, c.content)
+			assert.Equal(t, file, project.Path)
+		}
+	}
+
+	// asdf names Corretto and Zulu builds by their distribution version
+	project, err := ParseProjectFile(writeProjectFile(t, t.TempDir(), ToolVersionsFile, "java corretto-17.0.9.8.1\n"))
+	assert.NoError(t, err)
+	assert.True(t, project.Spec.Matches(&GetPackagesResponse{Distribution: "corretto", JavaVersion: "17.0.9+8", PackageType: "jdk"}))
+	project, err = ParseProjectFile(writeProjectFile(t, t.TempDir(), ToolVersionsFile, "java zulu-17.46.19\n"))
+	assert.NoError(t, err)
+	assert.True(t, project.Spec.Matches(&GetPackagesResponse{Distribution: "zulu", JavaVersion: "17.0.9+8", DistributionVersion: "17.46.19", PackageType: "jdk"}))
+	assert.False(t, project.Spec.Matches(&GetPackagesResponse{Distribution: "zulu", JavaVersion: "17.0.10+7", DistributionVersion: "17.48.15", PackageType: "jdk"}))
+	options := project.Spec.InstallOptions()
+	assert.Equal(t, 17, options.JDKVersion)
+	assert.Empty(t, options.Latest)
+
+	project, err = ParseProjectFile(writeProjectFile(t, t.TempDir(), ToolVersionsFile, "java system\n"))
+	assert.NoError(t, err)
+	assert.Nil(t, project.Spec)
+
+	_, err = ParseProjectFile(writeProjectFile(t, t.TempDir(), ToolVersionsFile, "nodejs 20.9.0\n"))
+	assert.ErrorIs(t, err, ErrProjectFileNotFound)
+	_, err = ParseProjectFile(writeProjectFile(t, t.TempDir(), SdkmanrcFile, "java=17.0.9-unknown\n"))
+	assert.ErrorContains(t, err, "unknown SDKMAN vendor")
+}
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	writeProjectFile(t, root, SdkmanrcFile, "java=17.0.9-tem\n")
+	writeProjectFile(t, path.Join(root, "tools"), ToolVersionsFile, "nodejs 20.9.0\n")
+	writeProjectFile(t, path.Join(root, "legacy"), JavaVersionFile, "11\n")
+	writeProjectFile(t, path.Join(root, "legacy"), ToolVersionsFile, "java zulu-8.0.392\n")
+
+	project, err := FindProjectFile(path.Join(root, "tools", "src"))
+	assert.NoError(t, err)
+	assert.Equal(t, path.Join(root, SdkmanrcFile), project.Path)
+	assert.Equal(t, "temurin", project.Spec.Distribution)
+
+	// .java-version comes first in a directory
+	project, err = FindProjectFile(path.Join(root, "legacy"))
+	assert.NoError(t, err)
+	assert.Equal(t, "11", project.Value)
+}
@@ -0,0 +1,264 @@
+package jlib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ReleaseInfo is the content of the release file shipped at the root of every JDK
+type ReleaseInfo struct {
+	JavaVersion        string            // JAVA_VERSION, e.g. "17.0.9" or "1.8.0_392"
+	JavaVersionDate    string            // JAVA_VERSION_DATE, e.g. "2023-10-17"
+	JavaRuntimeVersion string            // JAVA_RUNTIME_VERSION, e.g. "17.0.9+9"
+	Implementor        string            // IMPLEMENTOR, e.g. "Eclipse Adoptium"
+	ImplementorVersion string            // IMPLEMENTOR_VERSION, e.g. "Temurin-17.0.9+9"
+	OSName             string            // OS_NAME, e.g. "Linux"
+	OSArch             string            // OS_ARCH, e.g. "x86_64"
+	LibC               string            // LIBC, e.g. "gnu" or "musl"
+	Source             string            // SOURCE, the repositories and revisions the JDK was built from
+	Modules            []string          // MODULES, the modules of the runtime image
+	Properties         map[string]string // Every property of the file, including the ones above
+}
+
+// ParseReleaseFile parses a release file made of KEY="value" lines
+func ParseReleaseFile(r io.Reader) (*ReleaseInfo, error) {
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		properties[strings.TrimSpace(key)] = unquoteReleaseValue(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &ReleaseInfo{
+		JavaVersion:        properties["JAVA_VERSION"],
+		JavaVersionDate:    properties["JAVA_VERSION_DATE"],
+		JavaRuntimeVersion: properties["JAVA_RUNTIME_VERSION"],
+		Implementor:        properties["IMPLEMENTOR"],
+		ImplementorVersion: properties["IMPLEMENTOR_VERSION"],
+		OSName:             properties["OS_NAME"],
+		OSArch:             properties["OS_ARCH"],
+		LibC:               properties["LIBC"],
+		Source:             properties["SOURCE"],
+		Modules:            strings.Fields(properties["MODULES"]),
+		Properties:         properties,
+	}, nil
+}
+
+// ReadReleaseInfo parses the release file of the JDK installed in javaHome
+func ReadReleaseInfo(javaHome string) (*ReleaseInfo, error) {
+	f, err := os.Open(path.Join(javaHome, "release"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseReleaseFile(f)
+}
+
+func unquoteReleaseValue(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// HasModule reports whether the runtime image contains the module, e.g. "javafx.controls".
+// Release files of Java 8 list no modules.
+func (ri *ReleaseInfo) HasModule(name string) bool {
+	return slices.Contains(ri.Modules, name)
+}
+
+// MajorVersion returns the feature release number of JAVA_VERSION
+func (ri *ReleaseInfo) MajorVersion() (int, error) {
+	return majorVersion(ri.JavaVersion)
+}
+
+// Distribution returns the Disco distribution name matching IMPLEMENTOR
+func (ri *ReleaseInfo) Distribution() string {
+	return distributionFromImplementor(ri.Implementor)
+}
+
+// ReleaseMismatchError is returned when the release file of a package contradicts its metadata
+type ReleaseMismatchError struct {
+	Field    string // Name of the metadata field
+	Expected string // Value from the metadata
+	Actual   string // Value from the release file
+}
+
+func (e *ReleaseMismatchError) Error() string {
+	return fmt.Sprintf("release file does not match package metadata: %s is %s, expected %s", e.Field, e.Actual, e.Expected)
+}
+
+// Check cross-checks the release file against the metadata of the package, only fields set in meta are compared
+func (ri *ReleaseInfo) Check(meta *PackageMetaInfo) error {
+	expected := meta.MajorVersion
+	if expected == 0 {
+		expected = meta.JDKVersion
+	}
+	if expected != 0 {
+		major, err := ri.MajorVersion()
+		if err != nil {
+			return err
+		}
+		if major != expected {
+			return &ReleaseMismatchError{Field: "major_version", Expected: strconv.Itoa(expected), Actual: strconv.Itoa(major)}
+		}
+	}
+
+	if meta.Architecture != "" && ri.OSArch != "" && normalizeArch(meta.Architecture) != normalizeArch(ri.OSArch) {
+		return &ReleaseMismatchError{Field: "architecture", Expected: meta.Architecture, Actual: ri.OSArch}
+	}
+	if system, ok := releaseOperatingSystems[ri.OSName]; ok && meta.OperatingSystem != "" && normalizeOS(meta.OperatingSystem) != system {
+		return &ReleaseMismatchError{Field: "operating_system", Expected: meta.OperatingSystem, Actual: ri.OSName}
+	}
+	// Only the libc types of linux are told apart, macOS and windows releases report their own names
+	expectedLibC, actualLibC := normalizeLibC(meta.LibCType), normalizeLibC(ri.LibC)
+	if expectedLibC != "" && actualLibC != "" && expectedLibC != actualLibC {
+		return &ReleaseMismatchError{Field: "lib_c_type", Expected: meta.LibCType, Actual: ri.LibC}
+	}
+	return nil
+}
+
+// archAliases maps the architecture names of the Disco API and of OS_ARCH to a single name
+var archAliases = map[string]string{
+	"x86_64":  "x64",
+	"amd64":   "x64",
+	"x64":     "x64",
+	"aarch64": "aarch64",
+	"arm64":   "aarch64",
+	"x86":     "x86",
+	"i386":    "x86",
+	"i586":    "x86",
+	"i686":    "x86",
+	"x32":     "x86",
+	"arm":     "arm",
+	"arm32":   "arm",
+}
+
+// normalizeArch returns the name of an architecture with its aliases folded, e.g. x64 for x86_64 and amd64
+func normalizeArch(arch string) string {
+	arch = strings.ToLower(arch)
+	if alias, ok := archAliases[arch]; ok {
+		return alias
+	}
+	return arch
+}
+
+// normalizeOS returns the Disco operating system name with its linux variants folded into linux
+func normalizeOS(system string) string {
+	system = strings.ToLower(system)
+	if system == "alpine_linux" || system == "linux_musl" {
+		return "linux"
+	}
+	return system
+}
+
+// normalizeLibC returns glibc or musl for the Disco lib_c_type and the LIBC of the release file,
+// or an empty string for the libc types that are not compared
+func normalizeLibC(libc string) string {
+	switch strings.ToLower(libc) {
+	case "gnu", "glibc":
+		return "glibc"
+	case "musl":
+		return "musl"
+	}
+	return ""
+}
+
+// implementorDistributions maps the IMPLEMENTOR of the release file to the Disco distribution name
+var implementorDistributions = map[string]string{
+	"Eclipse Adoptium":   "temurin",
+	"AdoptOpenJDK":       "aoj",
+	"Azul Systems, Inc.": "zulu",
+	"Amazon.com Inc.":    "corretto",
+	"BellSoft":           "liberica",
+	"Oracle Corporation": "oracle",
+	"Microsoft":          "microsoft",
+	"SAP SE":             "sap_machine",
+	"Red Hat, Inc.":      "redhat",
+	"GraalVM Community":  "graalvm_community",
+	"Alibaba":            "dragonwell",
+	"Tencent":            "kona",
+	"JetBrains s.r.o.":   "jetbrains",
+	"International Business Machines Corporation": "semeru",
+}
+
+// distributionFromImplementor returns the Disco distribution name for an IMPLEMENTOR value
+func distributionFromImplementor(implementor string) string {
+	if distribution, ok := implementorDistributions[implementor]; ok {
+		return distribution
+	}
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(implementor)), " ", "_")
+}
+
+// majorVersion returns the feature release number of a Java version string, e.g. 8 for 1.8.0_392 and 17 for 17.0.9+9
+func majorVersion(version string) (int, error) {
+	v, err := ParseJavaVersion(version)
+	if err != nil {
+		return 0, err
+	}
+	return v.Feature(), nil
+}
+
+// releaseOperatingSystems maps the OS_NAME of the release file to the Disco operating system name
+var releaseOperatingSystems = map[string]string{
+	"Linux":   "linux",
+	"Darwin":  "macos",
+	"Windows": "windows",
+	"SunOS":   "solaris",
+	"AIX":     "aix",
+}
+
+// metaFromRelease synthesizes the metadata of a package that was not installed from the Disco API
+// from the release file found in its Java home
+func metaFromRelease(id string, home string) (*PackageMetaInfo, error) {
+	release, err := ReadReleaseInfo(home)
+	if err != nil {
+		return nil, err
+	}
+
+	javaVersion := release.JavaVersion
+	if javaVersion == "" {
+		return nil, fmt.Errorf("release file has no JAVA_VERSION")
+	}
+	major, err := release.MajorVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &PackageMetaInfo{}
+	meta.ID = id
+	meta.Distribution = release.Distribution()
+	meta.DistributionVersion = release.ImplementorVersion
+	meta.JavaVersion = javaVersion
+	meta.MajorVersion = major
+	meta.JDKVersion = major
+	meta.OperatingSystem = releaseOperatingSystems[release.OSName]
+	meta.Architecture = release.OSArch
+	meta.LibCType = release.LibC
+	meta.PackageType = "jdk"
+	if _, err := os.Stat(path.Join(home, "bin", addExeIfWindows("javac"))); err != nil {
+		meta.PackageType = "jre"
+	}
+	if strings.Contains(javaVersion, "-ea") {
+		meta.ReleaseStatus = "ea"
+	} else {
+		meta.ReleaseStatus = "ga"
+	}
+	return meta, nil
+}
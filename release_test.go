@@ -0,0 +1,121 @@
+package jlib
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTemurinRelease = `IMPLEMENTOR="Eclipse Adoptium"
+IMPLEMENTOR_VERSION="Temurin-17.0.9+9"
+JAVA_RUNTIME_VERSION="17.0.9+9"
+JAVA_VERSION="17.0.9"
+JAVA_VERSION_DATE="2023-10-17"
+LIBC="gnu"
+MODULES="java.base java.compiler java.datatransfer java.xml java.prefs java.desktop"
+OS_ARCH="x86_64"
+OS_NAME="Linux"
+SOURCE=".:git:4d8b2f4b5b38"
+`
+
+// makeTestJDK creates a minimal JDK layout with the given release file content in dir
+func makeTestJDK(t *testing.T, dir string, release string) string {
+	assert.NoError(t, os.MkdirAll(path.Join(dir, "bin"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(dir, "bin", addExeIfWindows("java")), []byte("#!/bin/sh\n"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(dir, "bin", addExeIfWindows("javac")), []byte("#!/bin/sh\n"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(dir, "release"), []byte(release), 0644))
+	return dir
+}
+
+func TestReadReleaseInfo(t *testing.T) {
+	dir := makeTestJDK(t, t.TempDir(), "# comment\n"+testTemurinRelease+"BROKEN LINE\n")
+	release, err := ReadReleaseInfo(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "Eclipse Adoptium", release.Implementor)
+	assert.Equal(t, "temurin", release.Distribution())
+	assert.Equal(t, "17.0.9", release.JavaVersion)
+	assert.Equal(t, "17.0.9+9", release.JavaRuntimeVersion)
+	assert.Equal(t, "gnu", release.LibC)
+	assert.Equal(t, "2023-10-17", release.Properties["JAVA_VERSION_DATE"])
+	assert.NotContains(t, release.Properties, "BROKEN LINE")
+	assert.Len(t, release.Modules, 6)
+	assert.True(t, release.HasModule("java.desktop"))
+	assert.False(t, release.HasModule("javafx.controls"))
+
+	_, err = ReadReleaseInfo(t.TempDir())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReleaseInfoCheck(t *testing.T) {
+	release := &ReleaseInfo{JavaVersion: "17.0.9"}
+	assert.NoError(t, release.Check(&PackageMetaInfo{}))
+	assert.NoError(t, release.Check(&PackageMetaInfo{JDKVersion: 17}))
+
+	err := release.Check(&PackageMetaInfo{MajorVersion: 21})
+	var mismatch *ReleaseMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, "21", mismatch.Expected)
+	assert.Equal(t, "17", mismatch.Actual)
+
+	release = &ReleaseInfo{JavaVersion: "17.0.9", OSName: "Linux", OSArch: "x86_64", LibC: "gnu"}
+	for _, meta := range []PackageMetaInfo{
+		{Architecture: "x64", OperatingSystem: "linux", LibCType: "glibc"},
+		{Architecture: "amd64", OperatingSystem: "linux"},
+		{Architecture: "x86_64"},
+		{OperatingSystem: "linux_musl"},
+	} {
+		assert.NoError(t, release.Check(&meta), meta)
+	}
+	for field, meta := range map[string]PackageMetaInfo{
+		"architecture":     {Architecture: "aarch64"},
+		"operating_system": {OperatingSystem: "macos"},
+		"lib_c_type":       {LibCType: "musl"},
+	} {
+		err := release.Check(&meta)
+		assert.ErrorAs(t, err, &mismatch, field)
+		assert.Equal(t, field, mismatch.Field)
+	}
+
+	release = &ReleaseInfo{OSName: "Darwin", OSArch: "aarch64", LibC: "default"}
+	assert.NoError(t, release.Check(&PackageMetaInfo{Architecture: "arm64", OperatingSystem: "macos", LibCType: "libc"}))
+}
+
+func TestMajorVersion(t *testing.T) {
+	for version, expected := range map[string]int{
+		"1.8.0_392":   8,
+		"17.0.9":      17,
+		"21":          21,
+		"22-ea":       22,
+		"11.0.21.0.1": 11,
+	} {
+		major, err := majorVersion(version)
+		assert.NoError(t, err, version)
+		assert.Equal(t, expected, major, version)
+	}
+	_, err := majorVersion("unknown")
+	assert.Error(t, err)
+}
+
+func TestMetaFromRelease(t *testing.T) {
+	dir := makeTestJDK(t, t.TempDir(), testTemurinRelease)
+	meta, err := metaFromRelease("jdk-17", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "jdk-17", meta.ID)
+	assert.Equal(t, "temurin", meta.Distribution)
+	assert.Equal(t, "17.0.9", meta.JavaVersion)
+	assert.Equal(t, 17, meta.JDKVersion)
+	assert.Equal(t, "linux", meta.OperatingSystem)
+	assert.Equal(t, "x86_64", meta.Architecture)
+	assert.Equal(t, "jdk", meta.PackageType)
+	assert.Equal(t, "ga", meta.ReleaseStatus)
+
+	assert.NoError(t, os.Remove(path.Join(dir, "bin", addExeIfWindows("javac"))))
+	meta, err = metaFromRelease("jre-17", dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "jre", meta.PackageType)
+
+	_, err = metaFromRelease("empty", makeTestJDK(t, t.TempDir(), "IMPLEMENTOR=\"Nobody\"\n"))
+	assert.Error(t, err)
+}
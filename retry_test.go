@@ -0,0 +1,142 @@
+package jlib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = &RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	MaxRetryAfter:  2 * time.Second,
+}
+
+// newFlakyServer fails the first n requests with the given status before serving body
+func newFlakyServer(n int32, status int, header http.Header, body string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= n {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	return srv, &calls
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("Metadata", func(t *testing.T) {
+		srv, calls := newFlakyServer(3, http.StatusServiceUnavailable, nil, `{"result":[{"id":"abc"}]}`)
+		defer srv.Close()
+		c := NewDiscoClient(srv.URL)
+		c.Retry = testRetryPolicy
+
+		result, err := c.GetPackages(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		srv, calls := newFlakyServer(10, http.StatusBadGateway, nil, "")
+		defer srv.Close()
+		c := NewDiscoClient(srv.URL)
+		c.Retry = testRetryPolicy
+
+		_, err := c.GetPackages(context.Background())
+		var apiErr *DiscoAPIError
+		assert.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Equal(t, int32(4), calls.Load())
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		srv, calls := newFlakyServer(10, http.StatusNotFound, nil, "")
+		defer srv.Close()
+		c := NewDiscoClient(srv.URL)
+		c.Retry = testRetryPolicy
+
+		_, err := c.GetPackages(context.Background())
+		assert.True(t, IsNotFound(err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("RetryAfter", func(t *testing.T) {
+		srv, calls := newFlakyServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}, "data")
+		defer srv.Close()
+		c := NewDiscoClient(srv.URL)
+		c.Retry = testRetryPolicy
+
+		start := time.Now()
+		dst := t.TempDir()
+		_, err := c.DownloadFile(context.Background(), srv.URL+"/jdk.zip", dst)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(2), calls.Load())
+
+		data, err := os.ReadFile(path.Join(dst, "jdk.zip"))
+		assert.NoError(t, err)
+		assert.Equal(t, "data", string(data))
+	})
+
+	t.Run("RetryAfterTooLong", func(t *testing.T) {
+		srv, calls := newFlakyServer(1, http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}}, "")
+		defer srv.Close()
+		c := NewDiscoClient(srv.URL)
+		c.Retry = testRetryPolicy
+
+		_, err := c.GetPackageRedirect(context.Background(), "abc")
+		assert.True(t, IsRateLimited(err))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		srv, _ := newFlakyServer(10, http.StatusServiceUnavailable, nil, "")
+		defer srv.Close()
+		c := NewDiscoClient(srv.URL)
+		c.Retry = &RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.GetPackages(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, 2*time.Second, p.backoff(2))
+	assert.Equal(t, 4*time.Second, p.backoff(3))
+	assert.Equal(t, 5*time.Second, p.backoff(4))
+	assert.Equal(t, 5*time.Second, p.backoff(40))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	d, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, d)
+
+	d, ok = parseRetryAfter("Mon, 01 Jan 2024 12:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,109 @@
+package jlib
+
+import (
+	"cmp"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// PackageSelector picks the package to install among the results of GetPackages
+type PackageSelector interface {
+	// Select returns the package to install or nil if none is acceptable
+	Select(packages []GetPackagesResponse) *GetPackagesResponse
+}
+
+// DefaultPackageSelector ranks the packages by release status (GA first), version (newest first),
+// preferred distribution, JavaFX, archive type and size (smallest first), the package ID breaks ties.
+// Packages built for another libc type would not run, they are only considered when no package matches LibCType.
+// The same results always lead to the same package whatever their order.
+type DefaultPackageSelector struct {
+	Distributions []string // Preferred distributions, earlier first, the others rank after them
+	JavaFX        bool     // Prefer packages bundling JavaFX, packages without it are preferred otherwise
+	LibCType      string   // Required libc type, e.g. "glibc" or "musl", any type when empty
+	ArchiveTypes  []string // Accepted archive types, earlier first, the types supported on the platform when empty
+}
+
+// NewDefaultPackageSelector returns a selector preferring the libc type of the current machine
+func NewDefaultPackageSelector() *DefaultPackageSelector {
+	return &DefaultPackageSelector{LibCType: hostLibCType()}
+}
+
+// hostLibCType returns the Disco libc type of the current machine
+func hostLibCType() string {
+	switch runtime.GOOS {
+	case "linux":
+		if musl, _ := filepath.Glob("/lib/ld-musl-*.so.1"); len(musl) > 0 {
+			return "musl"
+		}
+		return "glibc"
+	case "darwin":
+		return "libc"
+	case "windows":
+		return "c_std_lib"
+	}
+	return ""
+}
+
+// rank returns the position of value in preferred, len(preferred) if absent
+func rank(preferred []string, value string) int {
+	for i, p := range preferred {
+		if strings.EqualFold(p, value) {
+			return i
+		}
+	}
+	return len(preferred)
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 0
+	}
+	return 1
+}
+
+func (s *DefaultPackageSelector) Select(packages []GetPackagesResponse) *GetPackagesResponse {
+	archiveTypes := s.ArchiveTypes
+	if len(archiveTypes) == 0 {
+		archiveTypes = preferredArchiveTypes()
+	}
+
+	var candidates []*GetPackagesResponse
+	versions := map[*GetPackagesResponse]JavaVersion{}
+	for i := range packages {
+		pkg := &packages[i]
+		if !slices.Contains(archiveTypes, pkg.ArchiveType) {
+			continue
+		}
+		version, err := ParseJavaVersion(packageVersion(pkg))
+		if err != nil {
+			continue
+		}
+		versions[pkg] = version
+		candidates = append(candidates, pkg)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	if s.LibCType != "" {
+		matching := slices.DeleteFunc(slices.Clone(candidates), func(pkg *GetPackagesResponse) bool {
+			return !strings.EqualFold(pkg.LibCType, s.LibCType)
+		})
+		if len(matching) > 0 {
+			candidates = matching
+		}
+	}
+
+	return slices.MinFunc(candidates, func(a, b *GetPackagesResponse) int {
+		return cmp.Or(
+			cmp.Compare(boolRank(packageReleaseStatus(a) == "ga"), boolRank(packageReleaseStatus(b) == "ga")),
+			versions[b].Compare(versions[a]),
+			cmp.Compare(rank(s.Distributions, a.Distribution), rank(s.Distributions, b.Distribution)),
+			cmp.Compare(boolRank(a.JavaFXBundled == s.JavaFX), boolRank(b.JavaFXBundled == s.JavaFX)),
+			cmp.Compare(rank(archiveTypes, a.ArchiveType), rank(archiveTypes, b.ArchiveType)),
+			cmp.Compare(a.Size, b.Size),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+}
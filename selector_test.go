@@ -0,0 +1,104 @@
+package jlib
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultPackageSelector(t *testing.T) {
+	pkg := func(id string, distribution string, version string) GetPackagesResponse {
+		return GetPackagesResponse{ID: id, Distribution: distribution, JavaVersion: version, ArchiveType: "zip", Size: 100}
+	}
+
+	selector := &DefaultPackageSelector{ArchiveTypes: []string{"tar.gz", "zip"}}
+	packages := []GetPackagesResponse{
+		pkg("old", "temurin", "17.0.8+7"),
+		pkg("ea", "temurin", "17.0.10-ea+1"),
+		pkg("b", "zulu", "17.0.9+9"),
+		pkg("a", "temurin", "17.0.9+9"),
+		pkg("msi", "temurin", "17.0.11+1"),
+	}
+	packages[4].ArchiveType = "msi"
+
+	// The result does not depend on the order of the packages
+	for i := 0; i < 10; i++ {
+		rand.Shuffle(len(packages), func(i, j int) { packages[i], packages[j] = packages[j], packages[i] })
+		assert.Equal(t, "a", selector.Select(packages).ID)
+	}
+
+	selector.Distributions = []string{"zulu", "temurin"}
+	assert.Equal(t, "b", selector.Select(packages).ID)
+
+	// Only early access builds
+	assert.Equal(t, "ea", selector.Select([]GetPackagesResponse{pkg("ea", "temurin", "17.0.10-ea+1"), pkg("ea2", "temurin", "17.0.10-ea+0")}).ID)
+
+	fx := pkg("fx", "liberica", "21.0.1")
+	fx.JavaFXBundled = true
+	plain := pkg("plain", "liberica", "21.0.1")
+	musl := pkg("musl", "liberica", "21.0.1")
+	musl.LibCType = "musl"
+	tar := pkg("tar", "liberica", "21.0.1")
+	tar.ArchiveType = "tar.gz"
+	big := pkg("big", "liberica", "21.0.1")
+	big.Size = 200
+	candidates := []GetPackagesResponse{fx, plain, musl, tar, big}
+
+	selector = &DefaultPackageSelector{ArchiveTypes: []string{"tar.gz", "zip"}}
+	assert.Equal(t, "tar", selector.Select(candidates).ID)
+	selector.JavaFX = true
+	assert.Equal(t, "fx", selector.Select(candidates).ID)
+	selector = &DefaultPackageSelector{ArchiveTypes: []string{"zip"}, LibCType: "musl"}
+	assert.Equal(t, "musl", selector.Select(candidates).ID)
+	selector.LibCType = ""
+	assert.Equal(t, "plain", selector.Select([]GetPackagesResponse{big, plain}).ID)
+
+	assert.Nil(t, selector.Select(nil))
+}
+
+func TestDefaultPackageSelectorLibCType(t *testing.T) {
+	glibc := GetPackagesResponse{ID: "glibc", Distribution: "temurin", JavaVersion: "17.0.9+9", ArchiveType: "tar.gz", LibCType: "glibc"}
+	musl := GetPackagesResponse{ID: "musl", Distribution: "temurin", JavaVersion: "17.0.9+11", ArchiveType: "tar.gz", LibCType: "musl"}
+
+	// A newer build for another libc would not run
+	selector := &DefaultPackageSelector{ArchiveTypes: []string{"tar.gz"}, LibCType: "glibc"}
+	assert.Equal(t, "glibc", selector.Select([]GetPackagesResponse{musl, glibc}).ID)
+
+	// The other libc types are considered when none matches
+	assert.Equal(t, "musl", selector.Select([]GetPackagesResponse{musl}).ID)
+
+	selector.LibCType = ""
+	assert.Equal(t, "musl", selector.Select([]GetPackagesResponse{musl, glibc}).ID)
+}
+
+// firstSelector picks the first package, as Install did before selectors existed
+type firstSelector struct{}
+
+func (firstSelector) Select(packages []GetPackagesResponse) *GetPackagesResponse {
+	if len(packages) == 0 {
+		return nil
+	}
+	return &packages[0]
+}
+
+func TestVersionManagerSelector(t *testing.T) {
+	older := newTestJDKPackage(t, "older", "zulu17-older", "zulu", 17)
+	older.JavaVersion = "17.0.8"
+	newer := newTestJDKPackage(t, "newer", "zulu17-newer", "zulu", 17)
+	newer.JavaVersion = "17.0.9"
+	srv := newTestDiscoServer(t, older, newer)
+
+	vm := NewVersionManager(t.TempDir())
+	vm.Client = newTestDiscoClient(srv)
+	j, err := vm.Install(&JavaInstallOptions{Distribution: []string{"zulu"}, JDKVersion: 17})
+	assert.NoError(t, err)
+	assert.Equal(t, "newer", j.ID)
+
+	vm = NewVersionManager(t.TempDir())
+	vm.Client = newTestDiscoClient(srv)
+	vm.Selector = firstSelector{}
+	j, err = vm.Install(&JavaInstallOptions{Distribution: []string{"zulu"}, JDKVersion: 17})
+	assert.NoError(t, err)
+	assert.Equal(t, "older", j.ID)
+}
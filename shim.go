@@ -0,0 +1,186 @@
+package jlib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Name of the directory inside DataDir holding the shims, it is the only visible directory that is not a package
+const shimsDirName = "shims"
+
+// ShimExecCommand is the subcommand of ShimExecutable invoked by the shims as "shim-exec <DataDir> <name> args...",
+// it must call ShimExec of a VersionManager of that DataDir
+const ShimExecCommand = "shim-exec"
+
+// ShimsDir returns the directory to put first in PATH to use the shims
+func (vm *VersionManager) ShimsDir() string {
+	return path.Join(vm.DataDir, shimsDirName)
+}
+
+// shimNames returns the executables found in the bin directory of the packages, without .exe on windows
+func shimNames(javas []*JavaPackage) []string {
+	var names []string
+	for _, java := range javas {
+		entries, err := os.ReadDir(path.Join(java.JavaHome, "bin"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := executableName(entry)
+			if ok && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func executableName(entry os.DirEntry) (string, bool) {
+	if entry.IsDir() {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		name, ok := strings.CutSuffix(entry.Name(), ".exe")
+		return name, ok
+	}
+	info, err := entry.Info()
+	if err != nil || info.Mode().Perm()&0111 == 0 {
+		return "", false
+	}
+	return entry.Name(), true
+}
+
+// GenerateShims writes a launcher in ShimsDir for every executable of the installed packages, e.g. java, javac and jar.
+// A launcher runs "<ShimExecutable> shim-exec <DataDir> <name> args..." which resolves the current Java of the working
+// directory and executes its binary, see ShimExec. Launchers of executables no package provides anymore are removed.
+// The executable, os.Executable when ShimExecutable is empty, is recorded in the state to refresh the shims later.
+func (vm *VersionManager) GenerateShims() error {
+	executable := vm.ShimExecutable
+	if executable == "" {
+		var err error
+		if executable, err = os.Executable(); err != nil {
+			return fmt.Errorf("failed to find the shim executable: %w", err)
+		}
+	}
+	state, err := vm.LoadState()
+	if err != nil {
+		return err
+	}
+	if state.ShimExecutable != executable {
+		state.ShimExecutable = executable
+		if err := vm.saveState(state); err != nil {
+			return err
+		}
+	}
+	return vm.writeShims(executable)
+}
+
+// writeShims writes the launchers invoking executable and removes the others
+func (vm *VersionManager) writeShims(executable string) error {
+	javas, err := vm.List()
+	if err != nil {
+		return err
+	}
+
+	dir := vm.ShimsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	var files []string
+	for _, name := range shimNames(javas) {
+		file, content := shimScript(executable, filepath.FromSlash(vm.DataDir), name)
+		if err := os.WriteFile(path.Join(dir, file), []byte(content), 0755); err != nil {
+			return fmt.Errorf("failed to write shim: %w", err)
+		}
+		files = append(files, file)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !slices.Contains(files, entry.Name()) {
+			if err := os.Remove(path.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// shimScript returns the file name and content of the launcher of name
+func shimScript(executable string, dataDir string, name string) (string, string) {
+	if runtime.GOOS == "windows" {
+		return name + ".cmd", fmt.Sprintf("@echo off\r\nrem Generated by jlib, do not edit\r\n\"%s\" %s \"%s\" %s %%*\r\n", executable, ShimExecCommand, dataDir, name)
+	}
+	return name, fmt.Sprintf("#!/bin/sh\n# Generated by jlib, do not edit\nexec %s %s %s %s \"$@\"\n", quoteSh(executable), ShimExecCommand, quoteSh(dataDir), quoteSh(name))
+}
+
+// refreshShims regenerates the shims after packages changed, unless shims were never generated.
+// The shims keep invoking the executable they were generated for, not the program that changed the packages,
+// which may be a library user or a test binary. Failures are ignored, the shims are a convenience and the
+// install or removal itself succeeded.
+func (vm *VersionManager) refreshShims() {
+	if _, err := os.Stat(vm.ShimsDir()); err != nil {
+		return
+	}
+	executable := vm.ShimExecutable
+	if executable == "" {
+		state, err := vm.LoadState()
+		if err != nil || state.ShimExecutable == "" {
+			return
+		}
+		executable = state.ShimExecutable
+	}
+	vm.writeShims(executable)
+}
+
+// ShimExec runs the executable name of the current Java of the working directory with args, see Current.
+// JAVA_HOME is set to the selected Java. On success it does not return: the process is replaced by
+// the executable on unix, and exits with its exit code on windows.
+func (vm *VersionManager) ShimExec(name string, args []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	selection, err := vm.Current(wd)
+	if err != nil {
+		if selection != nil && selection.Spec != nil {
+			return fmt.Errorf("%s is not installed (%s): %w", selection.Spec, selection.Explain(), err)
+		}
+		return err
+	}
+
+	bin := filepath.Join(filepath.FromSlash(selection.Java.JavaHome), "bin", addExeIfWindows(name))
+	if _, err := os.Stat(bin); err != nil {
+		return fmt.Errorf("%s is not provided by %s (%s)", name, selection.Java.JavaHome, selection.Explain())
+	}
+	environ := ApplyEnv(os.Environ(), []EnvVar{{Name: "JAVA_HOME", Value: filepath.FromSlash(selection.Java.JavaHome)}})
+	return execReplace(bin, args, environ)
+}
+
+// lookPathOutside is exec.LookPath ignoring the shims directory, so that the system java is never a shim.
+// Directories are compared by identity since PATH may reach the shims through a symlink.
+func (vm *VersionManager) lookPathOutside(name string) (string, error) {
+	shims, _ := os.Stat(vm.ShimsDir())
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		if info, err := os.Stat(dir); err == nil && shims != nil && os.SameFile(info, shims) {
+			continue
+		}
+		if file, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in PATH", name)
+}
@@ -0,0 +1,114 @@
+package jlib
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionManagerGenerateShims(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shims are .cmd files on windows")
+	}
+	vm := NewVersionManager(t.TempDir())
+	vm.ShimExecutable = "/usr/local/bin/jlib"
+	for _, id := range []string{"jdk-17", "jre-17"} {
+		dir := makeTestJDK(t, path.Join(vm.DataDir, id), testTemurinRelease)
+		assert.NoError(t, os.WriteFile(path.Join(dir, "bin", "README"), []byte("not executable"), 0644))
+		_, err := vm.Repair(dir)
+		assert.NoError(t, err)
+	}
+	jre, err := vm.GetJavaByID("jre-17")
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(path.Join(jre.JavaDir, "bin", "javac")))
+	assert.NoError(t, os.WriteFile(path.Join(jre.JavaDir, "bin", "keytool"), []byte("#!/bin/sh\n"), 0755))
+
+	assert.NoError(t, vm.GenerateShims())
+	entries, err := os.ReadDir(vm.ShimsDir())
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"java", "javac", "keytool"}, names)
+
+	data, err := os.ReadFile(path.Join(vm.ShimsDir(), "javac"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "#!/bin/sh\n"))
+	assert.Contains(t, string(data), "exec '/usr/local/bin/jlib' shim-exec '"+vm.DataDir+"' 'javac' \"$@\"")
+
+	// The shims directory is not a package
+	result, err := vm.Scan()
+	assert.NoError(t, err)
+	assert.Empty(t, result.Problems)
+
+	// Removing a package updates the shims
+	assert.NoError(t, vm.Remove(jre))
+	assert.NoFileExists(t, path.Join(vm.ShimsDir(), "keytool"))
+	assert.FileExists(t, path.Join(vm.ShimsDir(), "javac"))
+}
+
+func TestVersionManagerRefreshShims(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shims are .cmd files on windows")
+	}
+	dataDir := t.TempDir()
+	vm := NewVersionManager(dataDir)
+	vm.ShimExecutable = "/usr/local/bin/jlib"
+	_, err := vm.Link("jdk-17", makeTestJDK(t, path.Join(t.TempDir(), "jdk-17"), testTemurinRelease))
+	assert.NoError(t, err)
+	assert.NoError(t, vm.GenerateShims())
+
+	// Another program changing the packages keeps the executable the shims were generated for
+	vm = NewVersionManager(dataDir)
+	home := makeTestJDK(t, path.Join(t.TempDir(), "jdk-21"), testTemurinRelease)
+	assert.NoError(t, os.WriteFile(path.Join(home, "bin", "jshell"), []byte("#!/bin/sh\n"), 0755))
+	_, err = vm.Link("jdk-21", home)
+	assert.NoError(t, err)
+	data, err := os.ReadFile(path.Join(vm.ShimsDir(), "jshell"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "exec '/usr/local/bin/jlib' shim-exec")
+	data, err = os.ReadFile(path.Join(vm.ShimsDir(), "java"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "exec '/usr/local/bin/jlib' shim-exec")
+
+	// Without a recorded executable the shims are left alone rather than pointed at this test binary
+	state, err := vm.LoadState()
+	assert.NoError(t, err)
+	state.ShimExecutable = ""
+	assert.NoError(t, vm.saveState(state))
+	assert.NoError(t, vm.Unlink("jdk-21"))
+	assert.FileExists(t, path.Join(vm.ShimsDir(), "jshell"))
+}
+
+func TestVersionManagerSystemJavaSkipsShims(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shims are .cmd files on windows")
+	}
+	vm := NewVersionManager(t.TempDir())
+	vm.ShimExecutable = "/usr/local/bin/jlib"
+	home := makeTestJDK(t, path.Join(t.TempDir(), "jdk-17"), testTemurinRelease)
+	_, err := vm.Link("jdk-17", home)
+	assert.NoError(t, err)
+	assert.NoError(t, vm.GenerateShims())
+	assert.FileExists(t, path.Join(vm.ShimsDir(), "java"))
+
+	// The shims are recognized whatever the spelling of their directory in PATH
+	alias := path.Join(t.TempDir(), "jlib")
+	assert.NoError(t, os.Symlink(vm.DataDir, alias))
+	for _, shims := range []string{vm.ShimsDir(), vm.ShimsDir() + "/", path.Join(alias, shimsDirName)} {
+		t.Setenv("PATH", shims+string(os.PathListSeparator)+path.Join(home, "bin"))
+		selection, err := vm.Current(t.TempDir())
+		assert.NoError(t, err)
+		assert.Equal(t, SourceSystem, selection.Source)
+		assert.Equal(t, path.Join(home, "bin", "java"), selection.Origin)
+
+		t.Setenv("PATH", shims)
+		_, err = vm.Current(t.TempDir())
+		assert.ErrorIs(t, err, ErrJavaNotFound)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package jlib
+
+import "syscall"
+
+// execReplace replaces the current process with the program
+func execReplace(program string, args []string, environ []string) error {
+	return syscall.Exec(program, append([]string{program}, args...), environ)
+}
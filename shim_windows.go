@@ -0,0 +1,24 @@
+package jlib
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// execReplace runs the program and exits with its exit code, windows cannot replace the current process
+func execReplace(program string, args []string, environ []string) error {
+	cmd := exec.Command(program, args...)
+	cmd.Env = environ
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
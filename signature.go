@@ -0,0 +1,120 @@
+package jlib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+const (
+	SignatureVerified = "verified" // The signature was checked against a trusted key
+	SignatureMissing  = "missing"  // The distribution does not publish a signature for the package
+)
+
+var ErrSignatureMissing = fmt.Errorf("package has no signature")
+
+// SignatureVerification is the result of checking the detached signature of a package, stored in meta.json
+type SignatureVerification struct {
+	Status       string    `json:"status"`                  // SignatureVerified or SignatureMissing
+	SignatureURI string    `json:"signature_uri,omitempty"` // Where the signature was downloaded from
+	Fingerprint  string    `json:"fingerprint,omitempty"`   // Fingerprint of the primary key of the signer
+	Signer       string    `json:"signer,omitempty"`        // Primary identity of the signer, e.g. "Adoptium <temurin-dev@eclipse.org>"
+	VerifiedAt   time.Time `json:"verified_at"`
+}
+
+// SignatureError is returned when the signature of a package cannot be verified against the keyring
+type SignatureError struct {
+	File         string // Path of the signed archive
+	SignatureURI string // Where the signature was downloaded from
+	Err          error
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("signature verification of %s failed: %v", e.File, e.Err)
+}
+
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// LoadKeyring reads trusted public keys from armored or binary OpenPGP key files
+func LoadKeyring(files ...string) (openpgp.EntityList, error) {
+	var keyring openpgp.EntityList
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var entities openpgp.EntityList
+		if isArmored(data) {
+			entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+		} else {
+			entities, err = openpgp.ReadKeyRing(bytes.NewReader(data))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keys from %s: %w", file, err)
+		}
+		keyring = append(keyring, entities...)
+	}
+	return keyring, nil
+}
+
+func isArmored(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN"))
+}
+
+// VerifyPackageSignature downloads the detached signature of the package defined by the given id
+// and checks the archive file against it. The signature may be armored or binary.
+// A package without signature yields a SignatureMissing result and no error,
+// a signature that does not match or is not made by a key of the keyring yields a *SignatureError.
+func (c *DiscoClient) VerifyPackageSignature(ctx context.Context, id string, file string, keyring openpgp.KeyRing) (*SignatureVerification, error) {
+	info, err := c.GetPackageInfo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if info.SignatureURI == "" {
+		return &SignatureVerification{Status: SignatureMissing, VerifiedAt: time.Now()}, nil
+	}
+
+	sig, err := c.fetchBody(ctx, info.SignatureURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signature: %w", err)
+	}
+
+	signed, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer signed.Close()
+
+	var signer *openpgp.Entity
+	if isArmored(sig) {
+		signer, err = openpgp.CheckArmoredDetachedSignature(keyring, signed, bytes.NewReader(sig), nil)
+	} else {
+		signer, err = openpgp.CheckDetachedSignature(keyring, signed, bytes.NewReader(sig), nil)
+	}
+	if err != nil {
+		return nil, &SignatureError{File: file, SignatureURI: info.SignatureURI, Err: err}
+	}
+	if signer == nil {
+		return nil, &SignatureError{File: file, SignatureURI: info.SignatureURI, Err: errors.New("unknown signer")}
+	}
+
+	result := &SignatureVerification{
+		Status:       SignatureVerified,
+		SignatureURI: info.SignatureURI,
+		Fingerprint:  strings.ToUpper(fmt.Sprintf("%x", signer.PrimaryKey.Fingerprint)),
+		VerifiedAt:   time.Now(),
+	}
+	if identity := signer.PrimaryIdentity(); identity != nil {
+		result.Signer = identity.Name
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package jlib
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestEntity(t *testing.T, name string) *openpgp.Entity {
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", nil)
+	assert.NoError(t, err)
+	return entity
+}
+
+func signTestPackage(t *testing.T, p *testPackage, signer *openpgp.Entity, armored bool) {
+	var sig bytes.Buffer
+	var err error
+	if armored {
+		err = openpgp.ArmoredDetachSign(&sig, signer, bytes.NewReader(p.Archive), nil)
+	} else {
+		err = openpgp.DetachSign(&sig, signer, bytes.NewReader(p.Archive), nil)
+	}
+	assert.NoError(t, err)
+	p.Signature = sig.Bytes()
+}
+
+func TestVersionManagerInstallSignature(t *testing.T) {
+	vendor := newTestEntity(t, "vendor")
+	attacker := newTestEntity(t, "attacker")
+
+	armored := newTestJDKPackage(t, "armored", "zulu17-armored", "zulu", 17)
+	signTestPackage(t, &armored, vendor, true)
+	binary := newTestJDKPackage(t, "binary", "zulu11-binary", "zulu", 11)
+	signTestPackage(t, &binary, vendor, false)
+	forged := newTestJDKPackage(t, "forged", "temurin17-forged", "temurin", 17)
+	signTestPackage(t, &forged, attacker, true)
+	unsigned := newTestJDKPackage(t, "unsigned", "corretto17-unsigned", "corretto", 17)
+	srv := newTestDiscoServer(t, armored, binary, forged, unsigned)
+
+	newVM := func() *VersionManager {
+		vm := NewVersionManager(t.TempDir())
+		vm.Client = newTestDiscoClient(srv)
+		vm.Keyring = openpgp.EntityList{vendor}
+		return vm
+	}
+
+	t.Run("Verified", func(t *testing.T) {
+		vm := newVM()
+		for _, version := range []int{17, 11} {
+			j, err := vm.Install(&JavaInstallOptions{Distribution: []string{"zulu"}, JDKVersion: version})
+			assert.NoError(t, err)
+			assert.Equal(t, SignatureVerified, j.Signature.Status)
+			assert.Equal(t, "vendor <vendor@example.com>", j.Signature.Signer)
+			assert.NotEmpty(t, j.Signature.Fingerprint)
+
+			meta, err := readStructFromJSONFile[packageMeta](path.Join(j.JavaDir, "meta.json"))
+			assert.NoError(t, err)
+			assert.Equal(t, SignatureVerified, meta.Signature.Status)
+		}
+	})
+
+	t.Run("Forged", func(t *testing.T) {
+		vm := newVM()
+		_, err := vm.Install(&JavaInstallOptions{Distribution: []string{"temurin"}, JDKVersion: 17})
+		var sigErr *SignatureError
+		assert.ErrorAs(t, err, &sigErr)
+		assert.NoDirExists(t, path.Join(vm.DataDir, "temurin17-forged"))
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		vm := newVM()
+		j, err := vm.Install(&JavaInstallOptions{Distribution: []string{"corretto"}, JDKVersion: 17})
+		assert.NoError(t, err)
+		assert.Equal(t, SignatureMissing, j.Signature.Status)
+
+		vm = newVM()
+		vm.RequireSignature = true
+		_, err = vm.Install(&JavaInstallOptions{Distribution: []string{"corretto"}, JDKVersion: 17})
+		assert.ErrorIs(t, err, ErrSignatureMissing)
+	})
+
+	t.Run("NoKeyring", func(t *testing.T) {
+		vm := newVM()
+		vm.Keyring = nil
+		j, err := vm.Install(&JavaInstallOptions{Distribution: []string{"temurin"}, JDKVersion: 17})
+		assert.NoError(t, err)
+		assert.Nil(t, j.Signature)
+	})
+}
+
+func TestLoadKeyring(t *testing.T) {
+	entity := newTestEntity(t, "vendor")
+	dir := t.TempDir()
+
+	var armored bytes.Buffer
+	w, err := armor.Encode(&armored, openpgp.PublicKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.Serialize(w))
+	assert.NoError(t, w.Close())
+	assert.NoError(t, os.WriteFile(path.Join(dir, "vendor.asc"), armored.Bytes(), 0644))
+
+	var binary bytes.Buffer
+	assert.NoError(t, entity.Serialize(&binary))
+	assert.NoError(t, os.WriteFile(path.Join(dir, "vendor.gpg"), binary.Bytes(), 0644))
+
+	keyring, err := LoadKeyring(path.Join(dir, "vendor.asc"), path.Join(dir, "vendor.gpg"))
+	assert.NoError(t, err)
+	assert.Len(t, keyring, 2)
+	assert.Equal(t, entity.PrimaryKey.Fingerprint, keyring[0].PrimaryKey.Fingerprint)
+
+	_, err = LoadKeyring(path.Join(dir, "missing.asc"))
+	assert.Error(t, err)
+}
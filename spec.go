@@ -0,0 +1,227 @@
+package jlib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JavaSpec describes the wanted Java package, see ParseJavaSpec for its string form
+type JavaSpec struct {
+	Distribution  string // Disco distribution name, any distribution when empty
+	Version       string // Version range, see ParseVersionRange, e.g. "17", "17.0.9", "~11", "17.0.9+", ">=17" or "17...21", any version when empty
+	LTS           bool   // Only long term support releases
+	ReleaseStatus string // "ga" or "ea", ga when empty
+	PackageType   string // "jdk" or "jre", any installed package type when empty, jdk when installing
+	JavaFX        bool   // Only packages bundling JavaFX
+
+	// Version of the distribution rather than of Java, e.g. "17.46.19" for Zulu, a prefix of the
+	// distribution_version of the packages. It has no string form, project files set it.
+	DistributionVersion string
+}
+
+// ParseJavaSpec parses a spec of the form [distribution@]version[,qualifier...], e.g. "temurin@17.0.9",
+// "zulu@~11", "21,ea", ">=17,lts" or "lts".
+//
+// The version is a range accepted by ParseVersionRange, e.g. a prefix ("17", "17.0.9", "~11", "11.0.x"),
+// a minimum ("17.0.9+"), a comparison (">=17") or a Disco range ("17...21"), or "lts" for any LTS version.
+// A lone distribution name ("temurin") accepts any version of that distribution.
+// The qualifiers are jdk, jre, fx (JavaFX bundled), ea, ga and lts.
+func ParseJavaSpec(s string) (*JavaSpec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty java spec")
+	}
+
+	spec := &JavaSpec{}
+	fields := strings.Split(s, ",")
+	version := strings.TrimSpace(fields[0])
+	if distribution, rest, ok := strings.Cut(version, "@"); ok {
+		spec.Distribution = strings.ToLower(strings.TrimSpace(distribution))
+		version = strings.TrimSpace(rest)
+		if spec.Distribution == "" {
+			return nil, fmt.Errorf("invalid java spec %q: empty distribution", s)
+		}
+	}
+
+	switch strings.ToLower(version) {
+	case "", "latest":
+	case "lts":
+		spec.LTS = true
+	default:
+		if spec.Distribution == "" && isDistributionName(version) {
+			spec.Distribution = strings.ToLower(version)
+			break
+		}
+		if _, err := ParseVersionRange(version); err != nil {
+			return nil, fmt.Errorf("invalid java spec %q: %w", s, err)
+		}
+		spec.Version = version
+		if strings.Contains(strings.ToLower(version), "-ea") {
+			spec.ReleaseStatus = "ea"
+		}
+	}
+
+	for _, qualifier := range fields[1:] {
+		switch q := strings.ToLower(strings.TrimSpace(qualifier)); q {
+		case "jdk", "jre":
+			spec.PackageType = q
+		case "ea", "ga":
+			spec.ReleaseStatus = q
+		case "fx":
+			spec.JavaFX = true
+		case "lts":
+			spec.LTS = true
+		default:
+			return nil, fmt.Errorf("invalid java spec %q: unknown qualifier %q", s, qualifier)
+		}
+	}
+	return spec, nil
+}
+
+// isDistributionName reports whether s is a word rather than a version, e.g. "temurin" or "sap_machine"
+func isDistributionName(s string) bool {
+	if s == "" || s[0] < 'A' || (s[0] > 'Z' && s[0] < 'a') || s[0] > 'z' {
+		return false
+	}
+	return !strings.ContainsAny(s, ".<>=~+ ")
+}
+
+// String returns the spec in the form accepted by ParseJavaSpec
+func (s *JavaSpec) String() string {
+	version := s.Version
+	if version == "" {
+		version = "latest"
+		if s.LTS {
+			version = "lts"
+		}
+	}
+	var b strings.Builder
+	if s.Distribution != "" {
+		b.WriteString(s.Distribution + "@")
+	}
+	b.WriteString(version)
+	if s.PackageType != "" {
+		b.WriteString("," + s.PackageType)
+	}
+	if s.ReleaseStatus == "ea" && !strings.Contains(strings.ToLower(s.Version), "-ea") {
+		b.WriteString(",ea")
+	}
+	if s.JavaFX {
+		b.WriteString(",fx")
+	}
+	if s.LTS && s.Version != "" {
+		b.WriteString(",lts")
+	}
+	return b.String()
+}
+
+func (s *JavaSpec) releaseStatus() string {
+	if s.ReleaseStatus == "" {
+		return "ga"
+	}
+	return s.ReleaseStatus
+}
+
+// InstallOptions returns the Disco query for the packages of the current platform that may match the spec.
+// Disco cannot express every constraint, the results still have to be filtered with Matches.
+func (s *JavaSpec) InstallOptions() *JavaInstallOptions {
+	options := &JavaInstallOptions{
+		OperatingSystem: GetOS(),
+		Architecture:    GetArch(),
+		ReleaseStatus:   []string{s.releaseStatus()},
+		PackageType:     s.PackageType,
+		JavaFXBundled:   s.JavaFX,
+	}
+	if s.Distribution != "" {
+		options.Distribution = []string{s.Distribution}
+	}
+	if options.PackageType == "" {
+		options.PackageType = "jdk"
+	}
+	if s.LTS {
+		options.TermOfSupport = []string{"lts"}
+	}
+
+	r, _ := ParseVersionRange(s.Version)
+	switch {
+	case s.DistributionVersion != "" && r.Min != nil:
+		// Disco cannot filter by distribution version, every package of the feature release is needed
+		options.JDKVersion = r.Min.Feature()
+	case r.IsAny() || r.Min == nil || r.Max == nil:
+		options.Latest = "available"
+	case r.Min.Compare(*r.Max) == 0 && len(r.Min.Numbers) < 3:
+		// A prefix shorter than feature.interim.update, e.g. 17 or 11.0.x, Disco only matches
+		// full versions exactly: ask for the latest of the feature release and filter with Matches
+		options.JDKVersion = r.Min.Feature()
+		options.Latest = "available"
+	default:
+		options.Version = r.String()
+	}
+	return options
+}
+
+// Matches reports whether an installed package or a Disco result satisfies the spec
+func (s *JavaSpec) Matches(pkg *GetPackagesResponse) bool {
+	if s.Distribution != "" && !strings.EqualFold(s.Distribution, pkg.Distribution) {
+		return false
+	}
+	if s.PackageType != "" && s.PackageType != pkg.PackageType {
+		return false
+	}
+	if s.JavaFX && !pkg.JavaFXBundled {
+		return false
+	}
+	if packageReleaseStatus(pkg) != s.releaseStatus() {
+		return false
+	}
+	if s.DistributionVersion != "" && pkg.DistributionVersion != s.DistributionVersion &&
+		!strings.HasPrefix(pkg.DistributionVersion, s.DistributionVersion+".") {
+		return false
+	}
+
+	version, err := ParseJavaVersion(packageVersion(pkg))
+	if err != nil {
+		return false
+	}
+	if s.LTS && !isLTS(pkg, version) {
+		return false
+	}
+	r, err := ParseVersionRange(s.Version)
+	if err != nil {
+		return false
+	}
+	return r.Contains(version)
+}
+
+// packageVersion returns the java version of a package, the major version if the full one is unknown
+func packageVersion(pkg *GetPackagesResponse) string {
+	switch {
+	case pkg.JavaVersion != "":
+		return pkg.JavaVersion
+	case pkg.MajorVersion != 0:
+		return strconv.Itoa(pkg.MajorVersion)
+	default:
+		return strconv.Itoa(pkg.JDKVersion)
+	}
+}
+
+func packageReleaseStatus(pkg *GetPackagesResponse) string {
+	if pkg.ReleaseStatus != "" {
+		return pkg.ReleaseStatus
+	}
+	if strings.Contains(pkg.JavaVersion, "-ea") {
+		return "ea"
+	}
+	return "ga"
+}
+
+// isLTS reports whether the package is a long term support release, from the Disco term of support
+// or from the release cadence: 8, 11 and every fourth release since 17
+func isLTS(pkg *GetPackagesResponse, version JavaVersion) bool {
+	if pkg.TermOfSupport != "" {
+		return strings.EqualFold(pkg.TermOfSupport, "lts")
+	}
+	major := version.Feature()
+	return major == 8 || major == 11 || (major >= 17 && (major-17)%4 == 0)
+}
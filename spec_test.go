@@ -0,0 +1,162 @@
+package jlib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJavaSpec(t *testing.T) {
+	for input, expected := range map[string]JavaSpec{
+		"17":                 {Version: "17"},
+		"temurin@17.0.9":     {Distribution: "temurin", Version: "17.0.9"},
+		"Zulu@~11":           {Distribution: "zulu", Version: "~11"},
+		"zulu@11.0.x":        {Distribution: "zulu", Version: "11.0.x"},
+		"17.0.9+":            {Version: "17.0.9+"},
+		">=17,lts":           {Version: ">=17", LTS: true},
+		"lts":                {LTS: true},
+		"latest":             {},
+		"temurin":            {Distribution: "temurin"},
+		"liberica@21,jre,fx": {Distribution: "liberica", Version: "21", PackageType: "jre", JavaFX: true},
+		"22-ea":              {Version: "22-ea", ReleaseStatus: "ea"},
+		"23,ea":              {Version: "23", ReleaseStatus: "ea"},
+	} {
+		spec, err := ParseJavaSpec(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, *spec, input)
+
+		again, err := ParseJavaSpec(spec.String())
+		assert.NoError(t, err, input)
+		assert.Equal(t, spec, again, input)
+	}
+
+	for _, input := range []string{"", "@17", "17,unknown", "temurin@abc", ">=x"} {
+		_, err := ParseJavaSpec(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestJavaSpecMatches(t *testing.T) {
+	pkg := func(distribution string, version string) *GetPackagesResponse {
+		major, _ := majorVersion(version)
+		return &GetPackagesResponse{Distribution: distribution, JavaVersion: version, MajorVersion: major, PackageType: "jdk"}
+	}
+	for spec, cases := range map[string]map[*GetPackagesResponse]bool{
+		"17": {
+			pkg("temurin", "17.0.9+9"): true,
+			pkg("zulu", "17"):          true,
+			pkg("zulu", "1.8.0_392"):   false,
+			pkg("zulu", "21.0.1"):      false,
+		},
+		"17>..21": {
+			pkg("zulu", "17.0.9"): false,
+			pkg("zulu", "19.0.2"): true,
+			pkg("zulu", "21.0.1"): true,
+			pkg("zulu", "22"):     false,
+		},
+		"temurin@17.0.9": {
+			pkg("temurin", "17.0.9+9"):   true,
+			pkg("temurin", "17.0.10+7"):  false,
+			pkg("zulu", "17.0.9"):        false,
+			pkg("temurin", "17.0.9-ea"):  false,
+			pkg("temurin", "17.0.9.1+1"): true,
+		},
+		"17.0.9+": {
+			pkg("zulu", "17.0.9"):  true,
+			pkg("zulu", "17.0.10"): true,
+			pkg("zulu", "21"):      true,
+			pkg("zulu", "17.0.8"):  false,
+		},
+		"8": {
+			pkg("zulu", "1.8.0_392"): true,
+			pkg("zulu", "18.0.2"):    false,
+		},
+		">=17,lts": {
+			pkg("zulu", "17.0.9"): true,
+			pkg("zulu", "21.0.1"): true,
+			pkg("zulu", "19.0.2"): false,
+			pkg("zulu", "11.0.1"): false,
+		},
+		"22-ea": {
+			pkg("zulu", "22-ea+3"): true,
+			pkg("zulu", "22"):      false,
+		},
+		"latest,jre": {
+			pkg("zulu", "21"): false,
+		},
+	} {
+		s, err := ParseJavaSpec(spec)
+		assert.NoError(t, err)
+		for p, expected := range cases {
+			assert.Equal(t, expected, s.Matches(p), "%s matches %s %s", spec, p.Distribution, p.JavaVersion)
+		}
+	}
+}
+
+func TestJavaSpecInstallOptions(t *testing.T) {
+	spec, _ := ParseJavaSpec("temurin@17.0.9")
+	options := spec.InstallOptions()
+	assert.Equal(t, []string{"temurin"}, options.Distribution)
+	assert.Equal(t, "17.0.9", options.Version)
+	assert.Equal(t, "jdk", options.PackageType)
+	assert.Equal(t, []string{"ga"}, options.ReleaseStatus)
+
+	spec, _ = ParseJavaSpec("zulu@11,fx")
+	options = spec.InstallOptions()
+	assert.Equal(t, 11, options.JDKVersion)
+	assert.Equal(t, "available", options.Latest)
+	assert.True(t, options.JavaFXBundled)
+
+	spec, _ = ParseJavaSpec("zulu@11.0.x")
+	options = spec.InstallOptions()
+	assert.Equal(t, 11, options.JDKVersion)
+	assert.Equal(t, "available", options.Latest)
+	assert.Empty(t, options.Version)
+
+	spec, _ = ParseJavaSpec("17.0")
+	options = spec.InstallOptions()
+	assert.Equal(t, 17, options.JDKVersion)
+	assert.Empty(t, options.Version)
+
+	spec, _ = ParseJavaSpec("17...21")
+	options = spec.InstallOptions()
+	assert.Equal(t, "17...21", options.Version)
+	assert.Zero(t, options.JDKVersion)
+
+	spec, _ = ParseJavaSpec("lts")
+	options = spec.InstallOptions()
+	assert.Equal(t, []string{"lts"}, options.TermOfSupport)
+}
+
+func TestVersionManagerSpec(t *testing.T) {
+	older := newTestJDKPackage(t, "older", "temurin17-older", "temurin", 17)
+	older.JavaVersion = "17.0.8+7"
+	newer := newTestJDKPackage(t, "newer", "temurin17-newer", "temurin", 17)
+	newer.JavaVersion = "17.0.9+9"
+	srv := newTestDiscoServer(t, older, newer)
+
+	vm := NewVersionManager(t.TempDir())
+	vm.Client = newTestDiscoClient(srv)
+
+	spec, err := ParseJavaSpec("temurin@17")
+	assert.NoError(t, err)
+	_, err = vm.UseSpec(spec)
+	assert.ErrorIs(t, err, ErrJavaNotFound)
+
+	j, err := vm.UseOrInstallSpec(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "newer", j.ID)
+
+	j, err = vm.UseSpec(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "newer", j.ID)
+
+	spec, _ = ParseJavaSpec("temurin@17.0.8")
+	j, err = vm.InstallSpec(spec)
+	assert.NoError(t, err)
+	assert.Equal(t, "older", j.ID)
+
+	spec, _ = ParseJavaSpec("zulu@17")
+	_, err = vm.InstallSpec(spec)
+	assert.ErrorContains(t, err, "no packages found")
+}
@@ -0,0 +1,158 @@
+package jlib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Name of the directory inside DataDir where packages are downloaded and extracted before being moved into place
+const stagingDirName = ".staging"
+
+// Name of the file holding the PID of the install owning a staging directory
+const stagingPIDFile = ".pid"
+
+// Staging directories without a PID file are considered left over by a killed install after this time,
+// the file is written right after the directory is created
+const stagingMaxAge = time.Hour
+
+// newStagingDir creates a private staging directory on the same file system as DataDir, so the package can be renamed into place.
+// The directories abandoned by earlier installs are removed first.
+func (vm *VersionManager) newStagingDir(dirname string) (string, error) {
+	if err := vm.CleanupStaging(); err != nil {
+		return "", err
+	}
+	root := path.Join(vm.DataDir, stagingDirName)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+	stage, err := os.MkdirTemp(root, dirname+"-")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path.Join(stage, stagingPIDFile), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		os.RemoveAll(stage)
+		return "", err
+	}
+	return stage, nil
+}
+
+// stagingAbandoned reports whether the staging directory no longer belongs to a running install:
+// the process whose PID it holds has exited, or it has no PID file and is older than stagingMaxAge
+func stagingAbandoned(dir string, info os.FileInfo) bool {
+	data, err := os.ReadFile(path.Join(dir, stagingPIDFile))
+	if err != nil {
+		return time.Since(info.ModTime()) > stagingMaxAge
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Since(info.ModTime()) > stagingMaxAge
+	}
+	return !processAlive(pid)
+}
+
+// CleanupStaging removes staging directories left over by installs that were interrupted.
+// Directories whose install process is still running are kept, however long the install takes.
+// It is called before each install.
+func (vm *VersionManager) CleanupStaging() error {
+	root := path.Join(vm.DataDir, stagingDirName)
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		dir := path.Join(root, entry.Name())
+		if stagingAbandoned(dir, info) {
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("failed to remove abandoned staging directory: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePackage checks that an extracted directory looks like a Java installation
+func validatePackage(dir string) error {
+	java := path.Join(javaHome(dir), "bin", addExeIfWindows("java"))
+	info, err := os.Stat(java)
+	if err != nil {
+		return fmt.Errorf("invalid package: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("invalid package: %s is a directory", java)
+	}
+	return nil
+}
+
+// commitPackage extracts the archive inside the staging directory, validates it, writes meta.json
+// and renames it to DataDir/dirname. The package is either fully installed or not present at all.
+func (vm *VersionManager) commitPackage(ctx context.Context, archive string, stage string, dirname string, meta *packageMeta) (*JavaPackage, error) {
+	staged, err := extractPackage(ctx, archive, stage)
+	if err != nil {
+		return nil, err
+	}
+	if release, err := ReadReleaseInfo(javaHome(staged)); err == nil {
+		if err := release.Check(&meta.PackageMetaInfo); err != nil {
+			return nil, err
+		}
+	}
+	return vm.movePackage(staged, dirname, meta)
+}
+
+// extractPackage extracts the archive into stage/package and checks that it contains a Java installation
+func extractPackage(ctx context.Context, archive string, stage string) (string, error) {
+	staged := path.Join(stage, "package")
+	if err := extractArchive(ctx, archive, staged); err != nil {
+		return "", fmt.Errorf("failed to extract package: %w", err)
+	}
+	if err := flattenSingleDir(staged); err != nil {
+		return "", fmt.Errorf("failed to extract package: %w", err)
+	}
+	if err := validatePackage(staged); err != nil {
+		return "", err
+	}
+	return staged, nil
+}
+
+// movePackage writes meta.json into the extracted package and renames it to DataDir/dirname
+func (vm *VersionManager) movePackage(staged string, dirname string, meta *packageMeta) (*JavaPackage, error) {
+	if err := saveStructToJSONFile(meta, path.Join(staged, "meta.json")); err != nil {
+		return nil, fmt.Errorf("failed to save package metadata: %w", err)
+	}
+
+	javaDir := path.Join(vm.DataDir, dirname)
+	metapath := path.Join(javaDir, "meta.json")
+	if _, err := os.Stat(javaDir); err == nil {
+		if _, err := os.Stat(metapath); err == nil {
+			// Installed concurrently by another process
+			installed, err := readStructFromJSONFile[packageMeta](metapath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read package metadata: %w", err)
+			}
+			return installed.javaPackage(javaDir), ErrPackageAlreadyInstalled
+		}
+		// Leftover of an install made before staging existed
+		if err := os.RemoveAll(javaDir); err != nil {
+			return nil, fmt.Errorf("failed to remove incomplete package: %w", err)
+		}
+	}
+
+	if err := os.Rename(staged, javaDir); err != nil {
+		return nil, fmt.Errorf("failed to move package into place: %w", err)
+	}
+
+	vm.refreshShims()
+	return meta.javaPackage(javaDir), nil
+}
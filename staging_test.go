@@ -0,0 +1,108 @@
+package jlib
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionManagerInstallStaging(t *testing.T) {
+	good := newTestJDKPackage(t, "good", "zulu17-good", "zulu", 17)
+	invalid := testPackage{
+		GetPackagesResponse: GetPackagesResponse{ID: "invalid", Filename: "temurin17-invalid.zip", ArchiveType: "zip", Distribution: "temurin", JDKVersion: 17},
+		Archive:             makeTestZip(t, map[string]string{"temurin17-invalid/README": "not a jdk"}),
+	}
+	mismatch := newTestJDKPackage(t, "mismatch", "temurin21-mismatch", "temurin", 21)
+	mismatch.Archive = makeTestZip(t, map[string]string{
+		"temurin21-mismatch/bin/java": "java",
+		"temurin21-mismatch/release":  "JAVA_VERSION=\"17.0.9\"\n",
+	})
+	srv := newTestDiscoServer(t, good, invalid, mismatch)
+
+	t.Run("Invalid", func(t *testing.T) {
+		vm := NewVersionManager(t.TempDir())
+		vm.Client = newTestDiscoClient(srv)
+
+		_, err := vm.Install(&JavaInstallOptions{Distribution: []string{"temurin"}, JDKVersion: 17})
+		assert.ErrorContains(t, err, "invalid package")
+		assert.NoDirExists(t, path.Join(vm.DataDir, "temurin17-invalid"))
+
+		staged, err := os.ReadDir(path.Join(vm.DataDir, stagingDirName))
+		assert.NoError(t, err)
+		assert.Empty(t, staged)
+
+		javas, err := vm.List()
+		assert.NoError(t, err)
+		assert.Empty(t, javas)
+	})
+
+	t.Run("ReleaseMismatch", func(t *testing.T) {
+		vm := NewVersionManager(t.TempDir())
+		vm.Client = newTestDiscoClient(srv)
+
+		_, err := vm.Install(&JavaInstallOptions{Distribution: []string{"temurin"}, JDKVersion: 21})
+		var mismatchErr *ReleaseMismatchError
+		assert.ErrorAs(t, err, &mismatchErr)
+		assert.NoDirExists(t, path.Join(vm.DataDir, "temurin21-mismatch"))
+	})
+
+	t.Run("ReplaceIncomplete", func(t *testing.T) {
+		vm := NewVersionManager(t.TempDir())
+		vm.Client = newTestDiscoClient(srv)
+		incomplete := path.Join(vm.DataDir, "zulu17-good")
+		assert.NoError(t, os.MkdirAll(path.Join(incomplete, "bin"), 0755))
+
+		j, err := vm.Install(&JavaInstallOptions{Distribution: []string{"zulu"}, JDKVersion: 17})
+		assert.NoError(t, err)
+		assert.Equal(t, incomplete, j.JavaDir)
+		assert.FileExists(t, path.Join(incomplete, "meta.json"))
+		assert.FileExists(t, path.Join(incomplete, "bin", "java"))
+	})
+}
+
+func TestVersionManagerCleanupStaging(t *testing.T) {
+	// A process that has exited, its PID is not reused this quickly
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	assert.NoError(t, cmd.Run())
+	exited := cmd.Process.Pid
+
+	dataDir := t.TempDir()
+	past := time.Now().Add(-2 * stagingMaxAge)
+	stage := func(name string, pid int, modTime time.Time) string {
+		dir := path.Join(dataDir, stagingDirName, name)
+		assert.NoError(t, os.MkdirAll(path.Join(dir, "package"), 0755))
+		if pid != 0 {
+			assert.NoError(t, os.WriteFile(path.Join(dir, stagingPIDFile), []byte(strconv.Itoa(pid)), 0644))
+		}
+		assert.NoError(t, os.Chtimes(dir, modTime, modTime))
+		return dir
+	}
+	running := stage("zulu17-running", os.Getpid(), past)
+	killed := stage("zulu17-killed", exited, time.Now())
+	old := stage("zulu17-old", 0, past)
+	recent := stage("zulu17-recent", 0, time.Now())
+
+	// Creating a VersionManager does not touch the data directory
+	vm := NewVersionManager(dataDir)
+	assert.DirExists(t, old)
+
+	assert.NoError(t, vm.CleanupStaging())
+	assert.DirExists(t, running, "a long install still in progress is kept")
+	assert.NoDirExists(t, killed)
+	assert.NoDirExists(t, old)
+	assert.DirExists(t, recent)
+
+	// Each install cleans up before staging, and marks its own directory
+	killed = stage("temurin21-killed", exited, time.Now())
+	dir, err := vm.newStagingDir("zulu17-new")
+	assert.NoError(t, err)
+	assert.NoDirExists(t, killed)
+	data, err := os.ReadFile(path.Join(dir, stagingPIDFile))
+	assert.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid()), string(data))
+}
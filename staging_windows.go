@@ -0,0 +1,13 @@
+package jlib
+
+import "os"
+
+// processAlive reports whether a process with the given PID is running, FindProcess fails on windows when there is none
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,222 @@
+package jlib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Name of the file inside DataDir recording the default and per-directory selections
+const stateFileName = "state.json"
+
+// State is the content of DataDir/state.json, selections are stored as JavaSpec strings
+type State struct {
+	Default     string            `json:"default,omitempty"`     // Global default
+	Directories map[string]string `json:"directories,omitempty"` // Overrides by absolute directory, they apply to subdirectories too
+
+	ShimExecutable string `json:"shim_executable,omitempty"` // Program the shims were generated for, reused when they are refreshed
+}
+
+// Sources of a Selection, from the highest precedence to the lowest
+const (
+	SourceProject   = "project"   // A project version file, see FindProjectFile
+	SourceDirectory = "directory" // A directory override, see SetDirectory
+	SourceDefault   = "default"   // The global default, see SetDefault
+	SourceSystem    = "system"    // The java found on PATH
+)
+
+// Selection is the Java chosen for a directory and the rule that chose it
+type Selection struct {
+	Java   *JavaPackage // Selected package, nil when the spec matches no installed package
+	Spec   *JavaSpec    // Requested spec, nil for the system java
+	Source string       // Rule that won: SourceProject, SourceDirectory, SourceDefault or SourceSystem
+	Origin string       // Project file, overridden directory, state file or java executable the selection comes from
+}
+
+// Explain describes in a sentence why this Java was selected
+func (s *Selection) Explain() string {
+	switch s.Source {
+	case SourceProject:
+		if s.Spec == nil {
+			return fmt.Sprintf("system java requested by %s", s.Origin)
+		}
+		return fmt.Sprintf("%s requested by %s", s.Spec, s.Origin)
+	case SourceDirectory:
+		return fmt.Sprintf("%s set for directory %s", s.Spec, s.Origin)
+	case SourceDefault:
+		return fmt.Sprintf("%s set as global default in %s", s.Spec, s.Origin)
+	default:
+		if s.Origin == "" {
+			return "no java selected and none found in PATH"
+		}
+		return fmt.Sprintf("system java found at %s", s.Origin)
+	}
+}
+
+func (vm *VersionManager) statePath() string {
+	return path.Join(vm.DataDir, stateFileName)
+}
+
+// LoadState reads DataDir/state.json, an empty state is returned when it does not exist
+func (vm *VersionManager) LoadState() (*State, error) {
+	state, err := readStructFromJSONFile[State](vm.statePath())
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	return state, nil
+}
+
+// saveState writes DataDir/state.json through a temporary file so that readers never see a partial state
+func (vm *VersionManager) saveState(state *State) error {
+	if err := os.MkdirAll(vm.DataDir, 0755); err != nil {
+		return err
+	}
+	tmp := vm.statePath() + ".tmp"
+	if err := saveStructToJSONFile(state, tmp); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return os.Rename(tmp, vm.statePath())
+}
+
+// SetDefault records spec as the global default, it must match an installed package. A nil spec removes the default.
+func (vm *VersionManager) SetDefault(spec *JavaSpec) error {
+	state, err := vm.LoadState()
+	if err != nil {
+		return err
+	}
+	state.Default = ""
+	if spec != nil {
+		if _, err := vm.UseSpec(spec); err != nil {
+			return err
+		}
+		state.Default = spec.String()
+	}
+	return vm.saveState(state)
+}
+
+// SetDirectory records spec as the selection of dir and its subdirectories, it must match an installed package.
+// A nil spec removes the override.
+func (vm *VersionManager) SetDirectory(dir string, spec *JavaSpec) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	state, err := vm.LoadState()
+	if err != nil {
+		return err
+	}
+	if spec == nil {
+		delete(state.Directories, dir)
+		return vm.saveState(state)
+	}
+
+	if _, err := vm.UseSpec(spec); err != nil {
+		return err
+	}
+	if state.Directories == nil {
+		state.Directories = map[string]string{}
+	}
+	state.Directories[dir] = spec.String()
+	return vm.saveState(state)
+}
+
+// directoryOverride returns the override of dir or of its closest parent
+func (state *State) directoryOverride(dir string) (string, string, bool) {
+	for {
+		if spec, ok := state.Directories[dir]; ok {
+			return dir, spec, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+// Current resolves the Java to use in dir. The first rule that applies wins: a project version file in dir
+// or its parents, a directory override, the global default and finally the java found on PATH.
+// When the winning spec matches no installed package, the selection is returned with ErrJavaNotFound
+// so that the caller may install it.
+func (vm *VersionManager) Current(dir string) (*Selection, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	selection, err := vm.currentSpec(dir)
+	if err != nil {
+		return nil, err
+	}
+	if selection.Spec == nil {
+		return vm.systemJava(selection)
+	}
+	selection.Java, err = vm.UseSpec(selection.Spec)
+	return selection, err
+}
+
+func (vm *VersionManager) currentSpec(dir string) (*Selection, error) {
+	project, err := FindProjectFile(dir)
+	if err == nil {
+		return &Selection{Spec: project.Spec, Source: SourceProject, Origin: project.Path}, nil
+	}
+	if !errors.Is(err, ErrProjectFileNotFound) {
+		return nil, err
+	}
+
+	state, err := vm.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	if overridden, value, ok := state.directoryOverride(dir); ok {
+		spec, err := ParseJavaSpec(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override of %s: %w", overridden, err)
+		}
+		return &Selection{Spec: spec, Source: SourceDirectory, Origin: overridden}, nil
+	}
+	if state.Default != "" {
+		spec, err := ParseJavaSpec(state.Default)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default: %w", err)
+		}
+		return &Selection{Spec: spec, Source: SourceDefault, Origin: vm.statePath()}, nil
+	}
+	return &Selection{Source: SourceSystem}, nil
+}
+
+// systemJava completes the selection with the java executable found on PATH, shims excluded
+func (vm *VersionManager) systemJava(selection *Selection) (*Selection, error) {
+	java, err := vm.lookPathOutside("java")
+	if err != nil {
+		return selection, ErrJavaNotFound
+	}
+	if selection.Source == SourceSystem {
+		selection.Origin = java
+	}
+	if real, err := filepath.EvalSymlinks(java); err == nil {
+		java = real
+	}
+
+	// bin/java inside the Java home, bin/java inside jre/ for Java 8 JDKs
+	home := filepath.ToSlash(filepath.Dir(filepath.Dir(java)))
+	if path.Base(home) == "jre" && isJavaDir(path.Dir(home)) {
+		home = path.Dir(home)
+	}
+	if pkg, err := loadExternalPackage(home); err == nil {
+		selection.Java = pkg
+		return selection, nil
+	}
+
+	meta := &PackageMetaInfo{}
+	meta.ID = "system"
+	selection.Java = newJavaPackage(meta, home)
+	selection.Java.JavaExecPath = filepath.ToSlash(java)
+	selection.Java.External = true
+	return selection, nil
+}
@@ -0,0 +1,84 @@
+package jlib
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionManagerCurrent(t *testing.T) {
+	vm := NewVersionManager(t.TempDir())
+	for _, id := range []string{"temurin-17", "zulu-21"} {
+		dir := makeTestJDK(t, path.Join(vm.DataDir, id), testTemurinRelease)
+		_, err := vm.Repair(dir)
+		assert.NoError(t, err)
+	}
+	zulu, err := vm.GetJavaByID("zulu-21")
+	assert.NoError(t, err)
+	zulu.Distribution, zulu.MajorVersion, zulu.JavaVersion = "zulu", 21, "21.0.1"
+	assert.NoError(t, saveStructToJSONFile(zulu.PackageMetaInfo, path.Join(zulu.JavaDir, "meta.json")))
+
+	// No java on PATH and nothing selected
+	t.Setenv("PATH", t.TempDir())
+	work := t.TempDir()
+	selection, err := vm.Current(work)
+	assert.ErrorIs(t, err, ErrJavaNotFound)
+	assert.Equal(t, SourceSystem, selection.Source)
+	assert.Equal(t, "no java selected and none found in PATH", selection.Explain())
+
+	temurin, _ := ParseJavaSpec("temurin@17")
+	assert.NoError(t, vm.SetDefault(temurin))
+	selection, err = vm.Current(work)
+	assert.NoError(t, err)
+	assert.Equal(t, SourceDefault, selection.Source)
+	assert.Equal(t, "temurin-17", selection.Java.ID)
+	assert.Contains(t, selection.Explain(), "global default")
+
+	missing, _ := ParseJavaSpec("corretto@11")
+	assert.ErrorIs(t, vm.SetDefault(missing), ErrJavaNotFound)
+
+	spec, _ := ParseJavaSpec("zulu@21")
+	assert.NoError(t, vm.SetDirectory(work, spec))
+	selection, err = vm.Current(path.Join(work, "sub", "dir"))
+	assert.NoError(t, err)
+	assert.Equal(t, SourceDirectory, selection.Source)
+	assert.Equal(t, work, selection.Origin)
+	assert.Equal(t, "zulu-21", selection.Java.ID)
+
+	writeProjectFile(t, path.Join(work, "project"), JavaVersionFile, "temurin-17\n")
+	selection, err = vm.Current(path.Join(work, "project"))
+	assert.NoError(t, err)
+	assert.Equal(t, SourceProject, selection.Source)
+	assert.Equal(t, "temurin-17", selection.Java.ID)
+	assert.Equal(t, "temurin@17 requested by "+path.Join(work, "project", JavaVersionFile), selection.Explain())
+
+	// A project asking for a version that is not installed
+	writeProjectFile(t, path.Join(work, "other"), JavaVersionFile, "corretto-11\n")
+	selection, err = vm.Current(path.Join(work, "other"))
+	assert.ErrorIs(t, err, ErrJavaNotFound)
+	assert.Equal(t, "corretto", selection.Spec.Distribution)
+
+	assert.NoError(t, vm.SetDirectory(work, nil))
+	assert.NoError(t, vm.SetDefault(nil))
+	state, err := vm.LoadState()
+	assert.NoError(t, err)
+	assert.Empty(t, state.Default)
+	assert.Empty(t, state.Directories)
+}
+
+func TestVersionManagerCurrentSystem(t *testing.T) {
+	bin := t.TempDir()
+	home := makeTestJDK(t, path.Join(t.TempDir(), "jdk-17"), testTemurinRelease)
+	assert.NoError(t, os.Symlink(path.Join(home, "bin", addExeIfWindows("java")), path.Join(bin, addExeIfWindows("java"))))
+	t.Setenv("PATH", bin)
+
+	vm := NewVersionManager(t.TempDir())
+	selection, err := vm.Current(t.TempDir())
+	assert.NoError(t, err)
+	assert.Equal(t, SourceSystem, selection.Source)
+	assert.Equal(t, path.Join(bin, addExeIfWindows("java")), selection.Origin)
+	assert.Equal(t, "temurin", selection.Java.Distribution)
+	assert.True(t, selection.Java.External)
+}
@@ -1,16 +1,13 @@
 package jlib
 
 import (
-	"archive/zip"
+	"context"
 	"encoding/json"
-	"fmt"
+	"hash"
 	"io"
-	"net/http"
 	"os"
 	"path"
-	"path/filepath"
 	"runtime"
-	"strings"
 )
 
 func GetOS() []string {
@@ -21,87 +18,67 @@ func GetArch() []string {
 	return []string{runtime.GOARCH}
 }
 
-// DownloadFile downloads file to dest directory
-func DownloadFile(url string, dest string) (*os.File, error) {
-	resp, err := http.Get(url)
+// DownloadFile downloads file to dest directory.
+// The partially written file is removed if the download fails or ctx is cancelled.
+func (c *DiscoClient) DownloadFile(ctx context.Context, url string, dest string) (*os.File, error) {
+	return c.downloadFile(ctx, url, dest, "", nil)
+}
+
+// downloadFile downloads file to dest directory as filename, or the last element of the url if empty.
+// The data is verified against checksum while streaming if not nil and of a known type.
+func (c *DiscoClient) downloadFile(ctx context.Context, url string, dest string, filename string, checksum *Checksum) (*os.File, error) {
+	if filename == "" {
+		filename = path.Base(url)
+	}
+
+	var h hash.Hash
+	if checksum != nil {
+		h = checksum.newHash()
+	}
+
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	out, err := os.Create(path.Join(dest, path.Base(url)))
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	out, err := os.Create(path.Join(dest, path.Base(filename)))
 	if err != nil {
 		return nil, err
 	}
 	defer out.Close()
 
-	_, err = io.Copy(out, resp.Body)
-	return out, err
-}
+	var w io.Writer = out
+	if h != nil {
+		w = io.MultiWriter(out, h)
+	}
 
-// src: https://stackoverflow.com/a/24792688
-func unzip(src, dest string) error {
-	r, err := zip.OpenReader(src)
-	if err != nil {
-		return err
+	if _, err = io.Copy(w, resp.Body); err == nil && h != nil {
+		err = checksum.verify(h, out.Name())
 	}
-	defer func() {
-		if err := r.Close(); err != nil {
-			panic(err)
-		}
-	}()
-
-	os.MkdirAll(dest, 0755)
-
-	// Closure to address file descriptors issue with all the deferred .Close() methods
-	extractAndWriteFile := func(f *zip.File) error {
-		rc, err := f.Open()
-		if err != nil {
-			return err
-		}
-		defer func() {
-			if err := rc.Close(); err != nil {
-				panic(err)
-			}
-		}()
-
-		path := filepath.Join(dest, f.Name)
-
-		// Check for ZipSlip (Directory traversal)
-		if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
-			return fmt.Errorf("illegal file path: %s", path)
-		}
-
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(path, f.Mode())
-		} else {
-			os.MkdirAll(filepath.Dir(path), f.Mode())
-			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-			if err != nil {
-				return err
-			}
-			defer func() {
-				if err := f.Close(); err != nil {
-					panic(err)
-				}
-			}()
-
-			_, err = io.Copy(f, rc)
-			if err != nil {
-				return err
-			}
-		}
-		return nil
+	if err != nil {
+		out.Close()
+		os.Remove(out.Name())
+		return nil, err
 	}
+	return out, nil
+}
 
-	for _, f := range r.File {
-		err := extractAndWriteFile(f)
-		if err != nil {
-			return err
-		}
-	}
+// contextReader fails reads once its context is done, so long copies can be interrupted
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
 
-	return nil
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
 }
 
 func saveStructToJSONFile[T any](data T, filename string) error {
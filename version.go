@@ -0,0 +1,298 @@
+package jlib
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// JavaVersion is a parsed java version number. Every format reported by Disco and the release file is accepted:
+// 1.8.0_392, 1.8.0_392-b08, 17.0.9+9, 21-ea+3, 11.0.21.0.1 or 17.0.9+9-LTS.
+type JavaVersion struct {
+	Numbers []int  // Version numbers, the legacy 1.x prefix removed: [8 0 392] for 1.8.0_392
+	Pre     string // Pre-release identifier, e.g. "ea"
+	Build   int    // Build number, 0 when unknown
+}
+
+// ParseJavaVersion parses a java version number
+func ParseJavaVersion(s string) (JavaVersion, error) {
+	v := JavaVersion{}
+	rest := strings.TrimSpace(s)
+	if strings.HasPrefix(rest, "1.") {
+		rest = rest[2:]
+	}
+
+	numbers := rest
+	if i := strings.IndexAny(rest, "+-"); i >= 0 {
+		numbers, rest = rest[:i], rest[i:]
+	} else {
+		rest = ""
+	}
+	for _, field := range strings.FieldsFunc(numbers, func(r rune) bool { return r == '.' || r == '_' }) {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return JavaVersion{}, fmt.Errorf("invalid java version %q", s)
+		}
+		v.Numbers = append(v.Numbers, n)
+	}
+	if len(v.Numbers) == 0 {
+		return JavaVersion{}, fmt.Errorf("invalid java version %q", s)
+	}
+
+	// -pre, then +build or the -bNN build of Java 8, then optional information such as -LTS
+	if strings.HasPrefix(rest, "-") {
+		field, after, _ := strings.Cut(rest[1:], "+")
+		if len(field) > 1 && field[0] == 'b' && isDigits(field[1:]) {
+			v.Build, _ = strconv.Atoi(field[1:])
+			return v, nil
+		}
+		v.Pre = field
+		if after == "" {
+			return v, nil
+		}
+		rest = "+" + after
+	}
+	if strings.HasPrefix(rest, "+") {
+		// Vendors append information after the build number, e.g. 17.0.9+9-LTS or 17.0.9+9_openj9-0.41.0
+		build := rest[1:]
+		if end := strings.IndexFunc(build, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			build = build[:end]
+		}
+		if build == "" && rest != "+" {
+			return JavaVersion{}, fmt.Errorf("invalid java version %q: invalid build number", s)
+		}
+		if build != "" {
+			v.Build, _ = strconv.Atoi(build)
+		}
+	}
+	return v, nil
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// Feature returns the feature release number, e.g. 17 for 17.0.9+9
+func (v JavaVersion) Feature() int {
+	if len(v.Numbers) == 0 {
+		return 0
+	}
+	return v.Numbers[0]
+}
+
+// IsPreRelease reports whether the version is an early access or other pre-release build
+func (v JavaVersion) IsPreRelease() bool {
+	return v.Pre != ""
+}
+
+// String returns the normalized version in the JEP 223 format, e.g. 8.0.392+8 for 1.8.0_392-b08 or 21-ea+3
+func (v JavaVersion) String() string {
+	parts := make([]string, len(v.Numbers))
+	for i, n := range v.Numbers {
+		parts[i] = strconv.Itoa(n)
+	}
+	s := strings.Join(parts, ".")
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	if v.Build != 0 {
+		s += "+" + strconv.Itoa(v.Build)
+	}
+	return s
+}
+
+// compareNumbers compares the version numbers only, missing numbers count as 0
+func (v JavaVersion) compareNumbers(o JavaVersion) int {
+	for i := 0; i < len(v.Numbers) || i < len(o.Numbers); i++ {
+		var x, y int
+		if i < len(v.Numbers) {
+			x = v.Numbers[i]
+		}
+		if i < len(o.Numbers) {
+			y = o.Numbers[i]
+		}
+		if x != y {
+			return cmp.Compare(x, y)
+		}
+	}
+	return 0
+}
+
+// Compare returns -1, 0 or 1 if v is older, the same or newer than o.
+// Version numbers are compared first, a pre-release precedes the release it leads to, e.g. 21-ea+35 < 21+35,
+// and builds of the same version are ordered by build number.
+func (v JavaVersion) Compare(o JavaVersion) int {
+	if c := v.compareNumbers(o); c != 0 {
+		return c
+	}
+	switch {
+	case v.Pre == "" && o.Pre != "":
+		return 1
+	case v.Pre != "" && o.Pre == "":
+		return -1
+	case v.Pre != o.Pre:
+		return strings.Compare(v.Pre, o.Pre)
+	}
+	return cmp.Compare(v.Build, o.Build)
+}
+
+// Less reports whether v is older than o
+func (v JavaVersion) Less(o JavaVersion) bool {
+	return v.Compare(o) < 0
+}
+
+// compareBound compares v to a range bound. A bound without pre-release and build only constrains
+// the version numbers it is written with, so that 17...21 contains 21.0.1 and 17..<18 excludes 18-ea.
+func (v JavaVersion) compareBound(bound JavaVersion) int {
+	if bound.Pre != "" || bound.Build != 0 {
+		return v.Compare(bound)
+	}
+	prefix := JavaVersion{Numbers: v.Numbers[:min(len(v.Numbers), len(bound.Numbers))]}
+	return prefix.compareNumbers(bound)
+}
+
+// VersionRange is a set of java versions between two optional bounds
+type VersionRange struct {
+	Min          *JavaVersion // Lower bound, none when nil
+	Max          *JavaVersion // Upper bound, none when nil
+	MinExclusive bool
+	MaxExclusive bool
+}
+
+// Disco range operators, see GetPackages
+var discoRangeOperators = []struct {
+	op                         string
+	minExclusive, maxExclusive bool
+}{
+	{">.<", true, true},
+	{"..<", false, true},
+	{">..", true, false},
+	{"...", false, false},
+}
+
+// ParseVersionRange parses the Disco range syntax documented on GetPackages: 17...21 includes both bounds,
+// 17..<21 excludes 21, 17>..21 excludes 17 and 17>.<21 excludes both.
+// Open ranges are written >=17, >17, <=21, <21 or 17.0.9+ (at least 17.0.9).
+// A single version is a prefix: 17 contains every 17 release, as do ~17 and 17.x.
+// An empty string or "latest" contains every version.
+func ParseVersionRange(s string) (VersionRange, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "latest" {
+		return VersionRange{}, nil
+	}
+
+	parse := func(s string) (*JavaVersion, error) {
+		v, err := ParseJavaVersion(s)
+		if err != nil {
+			return nil, err
+		}
+		return &v, nil
+	}
+
+	for _, r := range discoRangeOperators {
+		if low, high, ok := strings.Cut(s, r.op); ok {
+			min, err := parse(low)
+			if err != nil {
+				return VersionRange{}, err
+			}
+			max, err := parse(high)
+			if err != nil {
+				return VersionRange{}, err
+			}
+			return VersionRange{Min: min, Max: max, MinExclusive: r.minExclusive, MaxExclusive: r.maxExclusive}, nil
+		}
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(s, op) {
+			continue
+		}
+		v, err := parse(s[len(op):])
+		if err != nil {
+			return VersionRange{}, err
+		}
+		switch op {
+		case ">=":
+			return VersionRange{Min: v}, nil
+		case ">":
+			return VersionRange{Min: v, MinExclusive: true}, nil
+		case "<=":
+			return VersionRange{Max: v}, nil
+		default:
+			return VersionRange{Max: v, MaxExclusive: true}, nil
+		}
+	}
+
+	if strings.HasSuffix(s, "+") && !strings.Contains(s[:len(s)-1], "+") {
+		v, err := parse(s[:len(s)-1])
+		if err != nil {
+			return VersionRange{}, err
+		}
+		return VersionRange{Min: v}, nil
+	}
+
+	s = strings.TrimPrefix(s, "~")
+	s = strings.TrimSuffix(strings.TrimSuffix(s, ".x"), ".*")
+	return prefixRange(s)
+}
+
+// prefixRange returns the range of the versions starting with prefix, e.g. 17.0...17.0 for 17.0
+func prefixRange(prefix string) (VersionRange, error) {
+	v, err := ParseJavaVersion(prefix)
+	if err != nil {
+		return VersionRange{}, err
+	}
+	if v.Pre != "" && v.Build == 0 {
+		// Every build of the pre-release, the release status is checked separately
+		v.Pre = ""
+	}
+	return VersionRange{Min: &v, Max: &v}, nil
+}
+
+// Contains reports whether v is inside the range
+func (r VersionRange) Contains(v JavaVersion) bool {
+	if r.Min != nil {
+		c := v.compareBound(*r.Min)
+		if c < 0 || (c == 0 && r.MinExclusive) {
+			return false
+		}
+	}
+	if r.Max != nil {
+		c := v.compareBound(*r.Max)
+		if c > 0 || (c == 0 && r.MaxExclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsAny reports whether the range contains every version
+func (r VersionRange) IsAny() bool {
+	return r.Min == nil && r.Max == nil
+}
+
+// String returns the range in the form accepted by ParseVersionRange, the Disco syntax when both bounds are set
+func (r VersionRange) String() string {
+	switch {
+	case r.Min == nil && r.Max == nil:
+		return ""
+	case r.Min != nil && r.Max != nil && !r.MinExclusive && !r.MaxExclusive && slices.Equal(r.Min.Numbers, r.Max.Numbers) && r.Min.Compare(*r.Max) == 0:
+		return r.Min.String()
+	case r.Max == nil && r.MinExclusive:
+		return ">" + r.Min.String()
+	case r.Max == nil:
+		return ">=" + r.Min.String()
+	case r.Min == nil && r.MaxExclusive:
+		return "<" + r.Max.String()
+	case r.Min == nil:
+		return "<=" + r.Max.String()
+	}
+	for _, op := range discoRangeOperators {
+		if op.minExclusive == r.MinExclusive && op.maxExclusive == r.MaxExclusive {
+			return r.Min.String() + op.op + r.Max.String()
+		}
+	}
+	return ""
+}
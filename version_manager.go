@@ -1,19 +1,37 @@
 package jlib
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
 )
 
+// Name of the Disco API cache directory inside DataDir, directories starting with a dot are never packages
+const cacheDirName = ".cache"
+
 type VersionManager struct {
-	DataDir string // Path where JLib stores the data
+	DataDir          string          // Path where JLib stores the data
+	Client           *DiscoClient    // Disco API client used to find and download packages, DefaultDiscoClient if nil
+	Keyring          openpgp.KeyRing // Trusted vendor keys, package signatures are verified on install when set
+	RequireSignature bool            // Refuse to install packages that have no signature when Keyring is set
+	IncludeExternal  bool            // Include the JDKs found by Discover in List, Use and GetJavaByID
+	DiscoverRoots    []string        // Roots scanned when IncludeExternal is set, DefaultDiscoverRoots if empty
+	Selector         PackageSelector // Picks the package to install among the Disco results, NewDefaultPackageSelector if nil
+	ShimExecutable   string          // Program the shims invoke with the shim-exec subcommand, the one recorded by GenerateShims or os.Executable if empty
 }
 
+// NewVersionManager creates a VersionManager using a copy of DefaultDiscoClient, so that its settings
+// (mirror, HTTP client, retries) apply, whose Disco API responses are cached under dataDir/.cache.
 func NewVersionManager(dataDir string) *VersionManager {
-	return &VersionManager{DataDir: dataDir}
+	client := *DefaultDiscoClient
+	client.Cache = NewDiscoCache(path.Join(dataDir, cacheDirName))
+	return &VersionManager{DataDir: dataDir, Client: &client}
 }
 
 func NewDefaultVersionManager() (*VersionManager, error) {
@@ -24,6 +42,20 @@ func NewDefaultVersionManager() (*VersionManager, error) {
 	return NewVersionManager(path.Join(home, ".jlib")), nil
 }
 
+func (vm *VersionManager) client() *DiscoClient {
+	if vm.Client != nil {
+		return vm.Client
+	}
+	return DefaultDiscoClient
+}
+
+func (vm *VersionManager) selector() PackageSelector {
+	if vm.Selector != nil {
+		return vm.Selector
+	}
+	return NewDefaultPackageSelector()
+}
+
 type JavaInstallOptions = GetPackagesOptions
 
 func IsInstalled(err error) bool {
@@ -36,94 +68,236 @@ func IsNotInstalled(err error) bool {
 
 var ErrPackageAlreadyInstalled = fmt.Errorf("package already installed")
 
+// Install installs a package matching options, the Selector picks one when Disco returns several
 func (vm *VersionManager) Install(options *JavaInstallOptions) (*JavaPackage, error) {
-	options.ArchiveType = []string{"zip"}
+	return vm.InstallContext(context.Background(), options)
+}
+
+// InstallContext is like Install but aborts the download and extraction when ctx is done.
+// The package is downloaded and extracted in a staging directory and only moved into DataDir
+// once complete, on failure the staging directory is removed.
+func (vm *VersionManager) InstallContext(ctx context.Context, options *JavaInstallOptions) (*JavaPackage, error) {
+	query := *options
+	if len(query.ArchiveType) == 0 {
+		query.ArchiveType = preferredArchiveTypes()
+	}
 
-	packages, err := GetPackages(options)
+	packages, err := vm.client().GetPackages(ctx, &query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch packages: %w", err)
 	}
-	if len(packages) == 0 {
+	pkg := vm.selector().Select(packages)
+	if pkg == nil {
 		return nil, fmt.Errorf("no packages found")
 	}
+	return vm.installPackage(ctx, pkg)
+}
 
-	dirname, err := GetFilename(packages[0].ID)
+// installPackage downloads and installs a package returned by GetPackages
+func (vm *VersionManager) installPackage(ctx context.Context, pkg *GetPackagesResponse) (*JavaPackage, error) {
+	filename, err := vm.client().GetFilename(ctx, pkg.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get filename: %w", err)
 	}
 
-	dirname = strings.TrimSuffix(dirname, ".zip")
+	dirname := archiveBaseName(filename)
+	if java, err := vm.checkInstalled(dirname); err != nil {
+		return java, err
+	}
+
+	stage, err := vm.newStagingDir(dirname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stage)
+
+	file, err := vm.client().DownloadJavaByID(ctx, pkg.ID, stage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download package: %w", err)
+	}
+
+	meta := &packageMeta{PackageMetaInfo: *pkg}
+	if vm.Keyring != nil {
+		meta.Signature, err = vm.client().VerifyPackageSignature(ctx, pkg.ID, file.Name(), vm.Keyring)
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify package signature: %w", err)
+		}
+		if vm.RequireSignature && meta.Signature.Status != SignatureVerified {
+			return nil, ErrSignatureMissing
+		}
+	}
+
+	return vm.commitPackage(ctx, file.Name(), stage, dirname, meta)
+}
 
+// checkInstalled returns the package installed in DataDir/dirname with ErrPackageAlreadyInstalled, or nil if there is none
+func (vm *VersionManager) checkInstalled(dirname string) (*JavaPackage, error) {
 	metapath := path.Join(vm.DataDir, dirname, "meta.json")
-	_, err = os.Stat(metapath)
+	_, err := os.Stat(metapath)
 	if err == nil {
-		meta, err := readStructFromJSONFile[PackageMetaInfo](metapath)
+		meta, err := readStructFromJSONFile[packageMeta](metapath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read package metadata: %w", err)
 		}
 
-		return &JavaPackage{
-			PackageMetaInfo: meta,
-			JavaDir:         path.Join(vm.DataDir, dirname),
-			JavaExecPath:    path.Join(vm.DataDir, dirname, "bin", addExeIfWindows("java")),
-		}, ErrPackageAlreadyInstalled
+		return meta.javaPackage(path.Join(vm.DataDir, dirname)), ErrPackageAlreadyInstalled
 	}
 
 	if !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to check if package is installed: %w", err)
 	}
+	return nil, nil
+}
 
-	tmp := os.TempDir()
+// InstallFromArchive installs a zip or tar.gz JDK archive available locally, without network access.
+// The metadata is synthesized from the release file of the JDK when meta is nil, the package ID
+// is then the archive name without extension.
+func (vm *VersionManager) InstallFromArchive(archive string, meta *PackageMetaInfo) (*JavaPackage, error) {
+	return vm.InstallFromArchiveContext(context.Background(), archive, meta)
+}
 
-	file, err := DownloadJavaByID(packages[0].ID, tmp)
+// InstallFromArchiveContext is like InstallFromArchive but aborts the extraction when ctx is done
+func (vm *VersionManager) InstallFromArchiveContext(ctx context.Context, archive string, meta *PackageMetaInfo) (*JavaPackage, error) {
+	filename := path.Base(filepath.ToSlash(archive))
+	archiveType := archiveType(filename)
+	if archiveType == "" {
+		return nil, fmt.Errorf("unsupported archive type: %s", filename)
+	}
+	info, err := os.Stat(archive)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download package: %w", err)
+		return nil, err
 	}
 
-	if err := unzip(file.Name(), vm.DataDir); err != nil {
-		return nil, fmt.Errorf("failed to unzip package: %w", err)
+	dirname := archiveBaseName(filename)
+	if java, err := vm.checkInstalled(dirname); err != nil {
+		return java, err
 	}
 
-	err = saveStructToJSONFile(packages[0], path.Join(vm.DataDir, dirname, "meta.json"))
+	stage, err := vm.newStagingDir(dirname)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save package metadata: %w", err)
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stage)
+
+	if meta != nil {
+		return vm.commitPackage(ctx, archive, stage, dirname, &packageMeta{PackageMetaInfo: *meta})
+	}
+
+	staged, err := extractPackage(ctx, archive, stage)
+	if err != nil {
+		return nil, err
+	}
+	meta, err = metaFromRelease(dirname, javaHome(staged))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release file: %w", err)
 	}
+	meta.Filename = filename
+	meta.ArchiveType = archiveType
+	meta.Size = int(info.Size())
+	return vm.movePackage(staged, dirname, &packageMeta{PackageMetaInfo: *meta})
+}
 
-	return &JavaPackage{
-		PackageMetaInfo: &packages[0],
-		JavaDir:         path.Join(vm.DataDir, dirname),
-		JavaExecPath:    path.Join(vm.DataDir, dirname, "bin", addExeIfWindows("java")),
-	}, nil
+// ListProblem describes a directory of DataDir that is not a usable package
+type ListProblem struct {
+	Dir    string // Path of the directory
+	Reason string // Human readable description of the problem
+	Err    error  // Underlying error
 }
 
-func (vm *VersionManager) List() ([]*JavaPackage, error) {
+func (p ListProblem) Error() string {
+	return fmt.Sprintf("%s: %s: %v", p.Dir, p.Reason, p.Err)
+}
+
+// ListResult is the inventory of DataDir, broken directories are reported separately from valid packages
+type ListResult struct {
+	Packages []*JavaPackage
+	Problems []ListProblem
+}
+
+// Scan reads every package of DataDir and the packages registered with Link.
+// Directories without valid meta.json or java executable are reported as problems instead of failing
+// the whole inventory. Hidden directories and the shims directory belong to jlib and are skipped.
+func (vm *VersionManager) Scan() (*ListResult, error) {
 	files, err := os.ReadDir(vm.DataDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	var javas []*JavaPackage
+	result := &ListResult{}
 	for _, file := range files {
-		if file.IsDir() {
-			meta, err := readStructFromJSONFile[PackageMetaInfo](path.Join(vm.DataDir, file.Name(), "meta.json"))
-			if err != nil {
-				return nil, fmt.Errorf("failed to read package metadata: %w", err)
-			}
-			javas = append(javas, &JavaPackage{
-				PackageMetaInfo: meta,
-				JavaDir:         path.Join(vm.DataDir, file.Name()),
-				JavaExecPath:    path.Join(vm.DataDir, file.Name(), "bin", addExeIfWindows("java")),
-			})
+		if !file.IsDir() || strings.HasPrefix(file.Name(), ".") || file.Name() == shimsDirName {
+			continue
 		}
+		dir := path.Join(vm.DataDir, file.Name())
+
+		java, problem := loadPackage(dir)
+		if problem != nil {
+			result.Problems = append(result.Problems, *problem)
+			continue
+		}
+		result.Packages = append(result.Packages, java)
 	}
+	vm.scanLinks(result)
 
-	return javas, nil
+	return result, nil
 }
 
+func loadPackage(dir string) (*JavaPackage, *ListProblem) {
+	metapath := path.Join(dir, "meta.json")
+	if _, err := os.Stat(metapath); err != nil {
+		return nil, &ListProblem{Dir: dir, Reason: "missing meta.json", Err: err}
+	}
+	meta, err := readStructFromJSONFile[packageMeta](metapath)
+	if err != nil {
+		return nil, &ListProblem{Dir: dir, Reason: "invalid meta.json", Err: err}
+	}
+	if err := validatePackage(dir); err != nil {
+		return nil, &ListProblem{Dir: dir, Reason: "missing java executable", Err: err}
+	}
+	return meta.javaPackage(dir), nil
+}
+
+// List returns the valid packages of DataDir, see Scan for the broken ones.
+// The JDKs installed outside of jlib follow when IncludeExternal is set.
+func (vm *VersionManager) List() ([]*JavaPackage, error) {
+	result, err := vm.Scan()
+	if err != nil {
+		return nil, err
+	}
+	if vm.IncludeExternal {
+		result.Packages = append(result.Packages, vm.discoverExternal()...)
+	}
+	return result.Packages, nil
+}
+
+// Repair regenerates the meta.json of a package directory of DataDir from the release file of the JDK.
+// The directory name becomes the package ID since the Disco package it came from is unknown.
+func (vm *VersionManager) Repair(dir string) (*JavaPackage, error) {
+	if err := validatePackage(dir); err != nil {
+		return nil, err
+	}
+	meta, err := metaFromRelease(path.Base(dir), javaHome(dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release file: %w", err)
+	}
+	if err := saveStructToJSONFile(meta, path.Join(dir, "meta.json")); err != nil {
+		return nil, fmt.Errorf("failed to save package metadata: %w", err)
+	}
+	return newJavaPackage(meta, dir), nil
+}
+
+// Remove deletes an installed package, linked packages are unlinked and external packages are never deleted
 func (vm *VersionManager) Remove(java *JavaPackage) error {
+	if java.Linked {
+		return vm.Unlink(java.ID)
+	}
+	if java.External {
+		return ErrExternalPackage
+	}
 	if err := os.RemoveAll(java.JavaDir); err != nil {
 		return fmt.Errorf("failed to remove directory: %w", err)
 	}
+	vm.refreshShims()
 	return nil
 }
 
@@ -160,14 +334,87 @@ func (vm *VersionManager) Use(distribution string, jdkVersion int) (*JavaPackage
 
 // Get the Java version or install it if it doesn't exist
 func (vm *VersionManager) UseOrInstall(distribution string, jdkVersion int) (*JavaPackage, error) {
+	return vm.UseOrInstallContext(context.Background(), distribution, jdkVersion)
+}
+
+// UseOrInstallContext is like UseOrInstall but passes ctx to the installation
+func (vm *VersionManager) UseOrInstallContext(ctx context.Context, distribution string, jdkVersion int) (*JavaPackage, error) {
 	java, err := vm.Use(distribution, jdkVersion)
 	if errors.Is(err, ErrJavaNotFound) {
-		return vm.Install(&JavaInstallOptions{
+		return vm.InstallContext(ctx, &JavaInstallOptions{
 			Distribution:    []string{distribution},
 			JDKVersion:      jdkVersion,
 			OperatingSystem: GetOS(),
 			Architecture:    GetArch(),
 		})
 	}
-	return java, nil
+	return java, err
+}
+
+// UseSpec returns the newest installed package matching spec
+func (vm *VersionManager) UseSpec(spec *JavaSpec) (*JavaPackage, error) {
+	javas, err := vm.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *JavaPackage
+	var max JavaVersion
+	for _, java := range javas {
+		if !spec.Matches(java.PackageMetaInfo) {
+			continue
+		}
+		version, err := java.Version()
+		if err != nil {
+			continue
+		}
+		if found == nil || version.Compare(max) > 0 {
+			found, max = java, version
+		}
+	}
+	if found == nil {
+		return nil, ErrJavaNotFound
+	}
+	return found, nil
+}
+
+// InstallSpec installs the package of the current platform matching spec chosen by the Selector
+func (vm *VersionManager) InstallSpec(spec *JavaSpec) (*JavaPackage, error) {
+	return vm.InstallSpecContext(context.Background(), spec)
+}
+
+// InstallSpecContext is like InstallSpec but aborts the download and extraction when ctx is done
+func (vm *VersionManager) InstallSpecContext(ctx context.Context, spec *JavaSpec) (*JavaPackage, error) {
+	options := spec.InstallOptions()
+	options.ArchiveType = preferredArchiveTypes()
+
+	packages, err := vm.client().GetPackages(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch packages: %w", err)
+	}
+	var matching []GetPackagesResponse
+	for i := range packages {
+		if spec.Matches(&packages[i]) {
+			matching = append(matching, packages[i])
+		}
+	}
+	pkg := vm.selector().Select(matching)
+	if pkg == nil {
+		return nil, fmt.Errorf("no packages found for %s", spec)
+	}
+	return vm.installPackage(ctx, pkg)
+}
+
+// UseOrInstallSpec returns the newest installed package matching spec or installs one
+func (vm *VersionManager) UseOrInstallSpec(spec *JavaSpec) (*JavaPackage, error) {
+	return vm.UseOrInstallSpecContext(context.Background(), spec)
+}
+
+// UseOrInstallSpecContext is like UseOrInstallSpec but aborts the download and extraction when ctx is done
+func (vm *VersionManager) UseOrInstallSpecContext(ctx context.Context, spec *JavaSpec) (*JavaPackage, error) {
+	java, err := vm.UseSpec(spec)
+	if errors.Is(err, ErrJavaNotFound) {
+		return vm.InstallSpecContext(ctx, spec)
+	}
+	return java, err
 }
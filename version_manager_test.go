@@ -1,7 +1,13 @@
 package jlib
 
 import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
 	"path"
+	"runtime"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +20,104 @@ var testJavaOptions = &JavaInstallOptions{
 	Architecture:    GetArch(),
 }
 
+// makeTestZip builds a zip archive in memory from a map of file names to contents
+func makeTestZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(files[name]))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// newTestJDKPackage returns a zip package with a minimal JDK layout inside the dirname directory
+func newTestJDKPackage(t *testing.T, id string, dirname string, distribution string, jdkVersion int) testPackage {
+	return testPackage{
+		GetPackagesResponse: GetPackagesResponse{
+			ID:           id,
+			Filename:     dirname + ".zip",
+			ArchiveType:  "zip",
+			Distribution: distribution,
+			JDKVersion:   jdkVersion,
+			MajorVersion: jdkVersion,
+		},
+		Archive: makeTestZip(t, map[string]string{
+			dirname + "/bin/java": "#!/bin/sh\n",
+			dirname + "/release":  fmt.Sprintf("JAVA_VERSION=\"%d\"\n", jdkVersion),
+		}),
+	}
+}
+
+func TestVersionManagerInstallOffline(t *testing.T) {
+	good := newTestJDKPackage(t, "good", "zulu17-good", "zulu", 17)
+	bad := newTestJDKPackage(t, "bad", "temurin17-bad", "temurin", 17)
+	bad.Checksum = "0000000000000000000000000000000000000000000000000000000000000000"
+	srv := newTestDiscoServer(t, good, bad)
+
+	vm := NewVersionManager(t.TempDir())
+	vm.Client = newTestDiscoClient(srv)
+
+	j, err := vm.Install(&JavaInstallOptions{Distribution: []string{"zulu"}, JDKVersion: 17})
+	assert.NoError(t, err)
+	assert.Equal(t, "good", j.ID)
+	assert.FileExists(t, path.Join(j.JavaDir, "meta.json"))
+	assert.FileExists(t, path.Join(j.JavaDir, "bin", "java"))
+	assert.Equal(t, "17", j.Release.JavaVersion)
+
+	_, err = vm.Install(&JavaInstallOptions{Distribution: []string{"temurin"}, JDKVersion: 17})
+	var mismatch *ChecksumMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.NoDirExists(t, path.Join(vm.DataDir, "temurin17-bad"))
+}
+
+func TestVersionManagerInstallArchiveType(t *testing.T) {
+	zipPkg := newTestJDKPackage(t, "zip", "OpenJDK17U-jdk_x64_hotspot_17.0.9_9", "temurin", 17)
+	tarPkg := testPackage{
+		GetPackagesResponse: GetPackagesResponse{
+			ID:           "tar",
+			Filename:     "OpenJDK17U-jdk_x64_hotspot_17.0.9_9.tar.gz",
+			ArchiveType:  "tar.gz",
+			Distribution: "temurin",
+			JDKVersion:   17,
+		},
+		Archive: makeTestTarGz(t,
+			testArchiveEntry{Name: "jdk-17.0.9+9/bin/java", Body: "#!/bin/sh\n", Mode: 0755},
+			testArchiveEntry{Name: "jdk-17.0.9+9/release", Body: "JAVA_VERSION=\"17.0.9\"\n"},
+		),
+	}
+	srv := newTestDiscoServer(t, zipPkg, tarPkg)
+
+	vm := NewVersionManager(t.TempDir())
+	vm.Client = newTestDiscoClient(srv)
+
+	j, err := vm.Install(&JavaInstallOptions{Distribution: []string{"temurin"}, JDKVersion: 17})
+	assert.NoError(t, err)
+	if runtime.GOOS == "windows" {
+		assert.Equal(t, "zip", j.ID)
+	} else {
+		assert.Equal(t, "tar", j.ID)
+	}
+	assert.Equal(t, path.Join(vm.DataDir, "OpenJDK17U-jdk_x64_hotspot_17.0.9_9"), j.JavaDir)
+	assert.FileExists(t, j.JavaExecPath)
+	assert.FileExists(t, path.Join(j.JavaDir, "release"))
+
+	// An explicit archive type is respected
+	vm = NewVersionManager(t.TempDir())
+	vm.Client = newTestDiscoClient(srv)
+	j, err = vm.Install(&JavaInstallOptions{Distribution: []string{"temurin"}, JDKVersion: 17, ArchiveType: []string{"zip"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "zip", j.ID)
+}
+
 func TestVersionManager(t *testing.T) {
 	t.Run("Install", func(t *testing.T) {
 		if testing.Short() {
@@ -48,3 +152,109 @@ func TestVersionManager(t *testing.T) {
 		assert.NotEmpty(t, result)
 	})
 }
+
+func TestVersionManagerScan(t *testing.T) {
+	tmp := t.TempDir()
+	vm := NewVersionManager(tmp)
+
+	valid := makeTestJDK(t, path.Join(tmp, "jdk-17"), testTemurinRelease)
+	assert.NoError(t, saveStructToJSONFile(&PackageMetaInfo{ID: "abc"}, path.Join(valid, "meta.json")))
+	assert.NoError(t, os.MkdirAll(path.Join(tmp, "stray"), 0755))
+	noMeta := makeTestJDK(t, path.Join(tmp, "no-meta"), testTemurinRelease)
+	badMeta := makeTestJDK(t, path.Join(tmp, "bad-meta"), testTemurinRelease)
+	assert.NoError(t, os.WriteFile(path.Join(badMeta, "meta.json"), []byte("{"), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(tmp, "state.json"), []byte("{}"), 0644))
+
+	result, err := vm.Scan()
+	assert.NoError(t, err)
+	assert.Len(t, result.Packages, 1)
+	assert.Equal(t, "abc", result.Packages[0].ID)
+
+	reasons := map[string]string{}
+	for _, problem := range result.Problems {
+		reasons[path.Base(problem.Dir)] = problem.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"bad-meta": "invalid meta.json",
+		"no-meta":  "missing meta.json",
+		"stray":    "missing meta.json",
+	}, reasons)
+
+	javas, err := vm.List()
+	assert.NoError(t, err)
+	assert.Len(t, javas, 1)
+
+	j, err := vm.Repair(noMeta)
+	assert.NoError(t, err)
+	assert.Equal(t, "no-meta", j.ID)
+	assert.Equal(t, "temurin", j.Distribution)
+	assert.Equal(t, 17, j.JDKVersion)
+
+	_, err = vm.Repair(path.Join(tmp, "stray"))
+	assert.Error(t, err)
+
+	result, err = vm.Scan()
+	assert.NoError(t, err)
+	assert.Len(t, result.Packages, 2)
+	assert.Len(t, result.Problems, 2)
+}
+
+func TestVersionManagerListSkipsCache(t *testing.T) {
+	tmp := t.TempDir()
+	vm := NewVersionManager(tmp)
+	assert.NoError(t, os.MkdirAll(path.Join(tmp, cacheDirName), 0755))
+
+	result, err := vm.List()
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func TestNewVersionManagerUsesDefaultClient(t *testing.T) {
+	defaultClient := DefaultDiscoClient
+	t.Cleanup(func() { DefaultDiscoClient = defaultClient })
+	DefaultDiscoClient = NewDiscoClient("https://mirror.example.com/disco/v3.0")
+	DefaultDiscoClient.Retry = nil
+
+	vm := NewVersionManager(t.TempDir())
+	assert.Equal(t, "https://mirror.example.com/disco/v3.0", vm.Client.BaseURL)
+	assert.Nil(t, vm.Client.Retry)
+	assert.NotNil(t, vm.Client.Cache)
+	assert.Nil(t, DefaultDiscoClient.Cache)
+}
+
+func TestVersionManagerInstallFromArchive(t *testing.T) {
+	archive := writeTestArchive(t, "OpenJDK17U-jdk_x64_linux_hotspot_17.0.9_9.tar.gz", makeTestTarGz(t,
+		testArchiveEntry{Name: "jdk-17.0.9+9/bin/java", Body: "java", Mode: 0755},
+		testArchiveEntry{Name: "jdk-17.0.9+9/release", Body: testTemurinRelease},
+	))
+	vm := NewVersionManager(t.TempDir())
+
+	j, err := vm.InstallFromArchive(archive, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "OpenJDK17U-jdk_x64_linux_hotspot_17.0.9_9", j.ID)
+	assert.Equal(t, "temurin", j.Distribution)
+	assert.Equal(t, 17, j.JDKVersion)
+	assert.Equal(t, "tar.gz", j.ArchiveType)
+	assert.Equal(t, path.Join(vm.DataDir, j.ID), j.JavaDir)
+
+	javas, err := vm.List()
+	assert.NoError(t, err)
+	assert.Len(t, javas, 1)
+	assert.Equal(t, j.PackageMetaInfo, javas[0].PackageMetaInfo)
+
+	_, err = vm.InstallFromArchive(archive, nil)
+	assert.ErrorIs(t, err, ErrPackageAlreadyInstalled)
+
+	other := NewVersionManager(t.TempDir())
+	j, err = other.InstallFromArchive(archive, &PackageMetaInfo{ID: "abc", Distribution: "temurin", JDKVersion: 17})
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", j.ID)
+
+	other = NewVersionManager(t.TempDir())
+	_, err = other.InstallFromArchive(archive, &PackageMetaInfo{ID: "abc", JDKVersion: 21})
+	var mismatch *ReleaseMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+
+	_, err = vm.InstallFromArchive(writeTestArchive(t, "jdk.msi", []byte("msi")), nil)
+	assert.ErrorContains(t, err, "unsupported archive type")
+}
@@ -0,0 +1,94 @@
+package jlib
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJavaVersion(t *testing.T) {
+	for input, expected := range map[string]JavaVersion{
+		"1.8.0_392":     {Numbers: []int{8, 0, 392}},
+		"1.8.0_392-b08": {Numbers: []int{8, 0, 392}, Build: 8},
+		"17.0.9+9":      {Numbers: []int{17, 0, 9}, Build: 9},
+		"17.0.9+9-LTS":  {Numbers: []int{17, 0, 9}, Build: 9},
+		"21-ea+3":       {Numbers: []int{21}, Pre: "ea", Build: 3},
+		"22-ea":         {Numbers: []int{22}, Pre: "ea"},
+		"11.0.21.0.1":   {Numbers: []int{11, 0, 21, 0, 1}},
+		"17.0.0":        {Numbers: []int{17, 0, 0}},
+		"21":            {Numbers: []int{21}},
+	} {
+		v, err := ParseJavaVersion(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, v, input)
+	}
+
+	v, _ := ParseJavaVersion("1.8.0_392-b08")
+	assert.Equal(t, "8.0.392+8", v.String())
+	assert.Equal(t, 8, v.Feature())
+
+	for _, input := range []string{"", "abc", "17.x", "17+abc"} {
+		_, err := ParseJavaVersion(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestJavaVersionCompare(t *testing.T) {
+	ordered := []string{"1.8.0_382", "1.8.0_392-b08", "11.0.21", "11.0.21.0.1", "17-ea+3", "17-ea+35", "17", "17+35", "17.0.9+9", "17.0.10+7", "21-ea+3", "21.0.1"}
+	var versions []JavaVersion
+	for _, s := range ordered {
+		v, err := ParseJavaVersion(s)
+		assert.NoError(t, err, s)
+		versions = append(versions, v)
+	}
+	shuffled := slices.Clone(versions)
+	slices.Reverse(shuffled)
+	slices.SortFunc(shuffled, JavaVersion.Compare)
+	assert.Equal(t, versions, shuffled)
+
+	a, _ := ParseJavaVersion("17.0.0")
+	b, _ := ParseJavaVersion("17")
+	assert.Zero(t, a.Compare(b))
+	assert.False(t, a.Less(b))
+}
+
+func TestParseVersionRange(t *testing.T) {
+	for input, cases := range map[string]map[string]bool{
+		"":         {"8": true, "22-ea": true},
+		"17...21":  {"17": true, "17+35": true, "21.0.1": true, "16.0.2": false, "22": false},
+		"17..<21":  {"17": true, "20.0.2": true, "21": false, "21-ea+3": false},
+		"17>..21":  {"17.0.9": false, "18": true, "21.0.1": true},
+		"17>.<21":  {"17.0.9": false, "18": true, "21": false},
+		">=17.0.9": {"17.0.9+9": true, "17.0.8": false, "21": true},
+		"17.0.9+":  {"17.0.9+9": true, "17.0.8": false, "21": true},
+		"<11":      {"1.8.0_392": true, "11": false},
+		"17":       {"17": true, "17.0.9+9": true, "18-ea": false, "1.7.0": false},
+		"17.0":     {"17.0.9": true, "17.1": false},
+		"11.0.x":   {"11.0.21.0.1": true, "11.1": false},
+		"~11":      {"11.0.21": true, "12": false},
+		"8":        {"1.8.0_392": true, "18": false},
+		"17.0.9+9": {"17.0.9+9": true, "17.0.9+10": false},
+		"21-ea":    {"21-ea+3": true, "22-ea+1": false},
+	} {
+		r, err := ParseVersionRange(input)
+		assert.NoError(t, err, input)
+		for version, expected := range cases {
+			v, err := ParseJavaVersion(version)
+			assert.NoError(t, err, version)
+			assert.Equal(t, expected, r.Contains(v), "%q contains %s", input, version)
+		}
+	}
+
+	r, _ := ParseVersionRange("~17")
+	assert.Equal(t, "17", r.String())
+	r, _ = ParseVersionRange("17..<21")
+	assert.Equal(t, "17..<21", r.String())
+	r, _ = ParseVersionRange(">=17")
+	assert.Equal(t, ">=17", r.String())
+
+	for _, input := range []string{"abc", "17...x", ">=", "~"} {
+		_, err := ParseVersionRange(input)
+		assert.Error(t, err, input)
+	}
+}